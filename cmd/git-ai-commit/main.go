@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"git-ai-commit/internal/app"
+	"git-ai-commit/internal/git"
 )
 
 var (
@@ -17,9 +18,11 @@ var (
 type options struct {
 	context      string
 	contextFile  string
+	contextNotes string
 	prompt       string
 	promptFile   string
 	engine       string
+	language     string
 	amend        bool
 	addAll       bool
 	edit         bool
@@ -28,6 +31,16 @@ type options struct {
 	excludeFiles []string
 	debugPrompt  bool
 	debugCommand bool
+	noRules      bool
+	signoff      bool
+	coAuthors    []string
+	sign         bool
+	signKey      string
+	noVerify     bool
+	author       string
+	date         string
+	allowEmpty   bool
+	cleanup      string
 }
 
 func main() {
@@ -45,21 +58,35 @@ func main() {
 		printUsage(os.Stderr)
 		os.Exit(2)
 	}
-	if err := app.Run(
-		opts.context,
-		opts.contextFile,
-		opts.prompt,
-		opts.promptFile,
-		opts.engine,
-		opts.amend,
-		opts.addAll,
-		opts.edit,
-		opts.diff,
-		opts.includeFiles,
-		opts.excludeFiles,
-		opts.debugPrompt,
-		opts.debugCommand,
-	); err != nil {
+	if err := app.Run(app.Options{
+		Context:      opts.context,
+		ContextFile:  opts.contextFile,
+		ContextNotes: opts.contextNotes,
+		Prompt:       opts.prompt,
+		PromptFile:   opts.promptFile,
+		Engine:       opts.engine,
+		Language:     opts.language,
+		Amend:        opts.amend,
+		AddAll:       opts.addAll,
+		Edit:         opts.edit,
+		ShowDiff:     opts.diff,
+		IncludeFiles: opts.includeFiles,
+		ExcludeFiles: opts.excludeFiles,
+		DebugPrompt:  opts.debugPrompt,
+		DebugCommand: opts.debugCommand,
+		NoRules:      opts.noRules,
+		Signoff:      opts.signoff,
+		CoAuthors:    opts.coAuthors,
+		Commit: git.CommitOptions{
+			Sign:       opts.sign,
+			SignKey:    opts.signKey,
+			NoVerify:   opts.noVerify,
+			Author:     opts.author,
+			Date:       opts.date,
+			AllowEmpty: opts.allowEmpty,
+			Cleanup:    opts.cleanup,
+		},
+	}); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -82,7 +109,7 @@ func parseArgs(args []string) (options, error) {
 				return opts, errHelp
 			case "version":
 				return opts, errVersion
-			case "context", "context-file", "prompt", "prompt-file", "engine", "include", "exclude":
+			case "context", "context-file", "context-from-notes", "prompt", "prompt-file", "engine", "language", "include", "exclude", "co-author", "author", "date", "cleanup":
 				if !hasValue {
 					if i+1 >= len(args) {
 						return opts, fmt.Errorf("missing value for --%s", name)
@@ -105,6 +132,17 @@ func parseArgs(args []string) (options, error) {
 				opts.debugPrompt = true
 			case "debug-command":
 				opts.debugCommand = true
+			case "no-rules":
+				opts.noRules = true
+			case "signoff":
+				opts.signoff = true
+			case "gpg-sign":
+				opts.sign = true
+				opts.signKey = value
+			case "no-verify":
+				opts.noVerify = true
+			case "allow-empty":
+				opts.allowEmpty = true
 			default:
 				return opts, fmt.Errorf("unknown option --%s", name)
 			}
@@ -122,6 +160,10 @@ func parseArgs(args []string) (options, error) {
 	if opts.prompt != "" && opts.promptFile != "" {
 		return opts, fmt.Errorf("cannot use both --prompt and --prompt-file")
 	}
+	// The editor needs stdin, which --context-file - has already read
+	if opts.contextFile == "-" && (opts.edit || opts.diff) {
+		return opts, fmt.Errorf("cannot use --context-file - with --edit or --diff: the editor needs stdin; write the context to a file instead")
+	}
 	return opts, nil
 }
 
@@ -131,12 +173,19 @@ func applyLongOption(opts *options, name, value string) error {
 		opts.context = value
 	case "context-file":
 		opts.contextFile = value
+	case "context-from-notes":
+		if value == "" {
+			return fmt.Errorf("missing value for --context-from-notes")
+		}
+		opts.contextNotes = value
 	case "prompt":
 		opts.prompt = value
 	case "prompt-file":
 		opts.promptFile = value
 	case "engine":
 		opts.engine = value
+	case "language":
+		opts.language = value
 	case "include":
 		if value == "" {
 			return fmt.Errorf("missing value for --include")
@@ -147,6 +196,23 @@ func applyLongOption(opts *options, name, value string) error {
 			return fmt.Errorf("missing value for --exclude")
 		}
 		opts.excludeFiles = append(opts.excludeFiles, value)
+	case "co-author":
+		if value == "" {
+			return fmt.Errorf("missing value for --co-author")
+		}
+		opts.coAuthors = append(opts.coAuthors, value)
+	case "author", "date", "cleanup":
+		if value == "" {
+			return fmt.Errorf("missing value for --%s", name)
+		}
+		switch name {
+		case "author":
+			opts.author = value
+		case "date":
+			opts.date = value
+		case "cleanup":
+			opts.cleanup = value
+		}
 	default:
 		return fmt.Errorf("unknown option --%s", name)
 	}
@@ -206,6 +272,15 @@ func parseShortOptions(opts *options, arg string, args []string, index *int) err
 			opts.diff = true
 		case 'e':
 			opts.edit = true
+		case 's':
+			opts.signoff = true
+		case 'S':
+			// Like git, the rest of the cluster is the key ID.
+			opts.sign = true
+			opts.signKey = cluster[i+1:]
+			i = len(cluster)
+		case 'n':
+			opts.noVerify = true
 		case 'h':
 			return errHelp
 		default:
@@ -221,16 +296,27 @@ func printUsage(out *os.File) {
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Options:")
 	fmt.Fprintln(out, "  --context VALUE           Additional context for the commit message")
-	fmt.Fprintln(out, "  --context-file VALUE      Path to a file containing additional context")
+	fmt.Fprintln(out, "  --context-file VALUE      Path to a file containing additional context (- reads stdin)")
+	fmt.Fprintln(out, "  --context-from-notes REF  Add the git note attached to HEAD in the notes ref as context")
 	fmt.Fprintln(out, "  --prompt VALUE            Bundled prompt preset: default, conventional, gitmoji, karma")
 	fmt.Fprintln(out, "  --prompt-file VALUE       Path to a custom prompt file")
 	fmt.Fprintln(out, "  --engine VALUE            LLM engine name override")
+	fmt.Fprintln(out, "  --language VALUE          Language of the commit message, e.g. ja or English")
 	fmt.Fprintln(out, "  --amend                   Amend the previous commit")
 	fmt.Fprintln(out, "  -d, --diff                Show staged diff in the editor (implies --edit)")
 	fmt.Fprintln(out, "  -e, --edit                Open the generated commit message in an editor before committing")
 	fmt.Fprintln(out, "  -a, --all                 Stage modified and deleted files before generating the message")
 	fmt.Fprintln(out, "  -i, --include VALUE       Stage specific files before generating the message")
 	fmt.Fprintln(out, "  -x, --exclude VALUE       Hide specific files from the diff for message generation")
+	fmt.Fprintln(out, "  -s, --signoff             Add a Signed-off-by trailer")
+	fmt.Fprintln(out, "  --co-author VALUE         Add a Co-authored-by trailer (\"Name <email>\", alias or author in git log)")
+	fmt.Fprintln(out, "  -S, --gpg-sign[=KEYID]    GPG/SSH-sign the commit")
+	fmt.Fprintln(out, "  -n, --no-verify           Skip the pre-commit and commit-msg hooks")
+	fmt.Fprintln(out, "  --author VALUE            Override the commit author")
+	fmt.Fprintln(out, "  --date VALUE              Override the author date")
+	fmt.Fprintln(out, "  --allow-empty             Allow a commit without changes (describe it with --context)")
+	fmt.Fprintln(out, "  --cleanup VALUE           How git cleans up the message: strip, whitespace, verbatim, scissors, default")
+	fmt.Fprintln(out, "  --no-rules                Always call the engine, even for trivial changes")
 	fmt.Fprintln(out, "  --debug-prompt            Print the prompt before executing the engine")
 	fmt.Fprintln(out, "  --debug-command           Print the engine command before execution")
 	fmt.Fprintln(out, "  -h, --help                Show help")
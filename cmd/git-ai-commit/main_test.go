@@ -139,3 +139,81 @@ func TestParseArgs_EditShortCluster(t *testing.T) {
 		t.Error("expected edit to be true")
 	}
 }
+
+func TestParseArgs_NoRules(t *testing.T) {
+	opts, err := parseArgs([]string{"--no-rules"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.noRules {
+		t.Error("expected noRules=true")
+	}
+}
+
+func TestParseArgs_Trailers(t *testing.T) {
+	opts, err := parseArgs([]string{"-as", "--co-author", "jane", "--co-author=Bob <bob@example.com>"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.signoff || !opts.addAll {
+		t.Errorf("expected signoff and addAll, got %+v", opts)
+	}
+	if len(opts.coAuthors) != 2 || opts.coAuthors[0] != "jane" || opts.coAuthors[1] != "Bob <bob@example.com>" {
+		t.Errorf("coAuthors = %q", opts.coAuthors)
+	}
+	if _, err := parseArgs([]string{"--co-author="}); err == nil {
+		t.Error("expected error for empty --co-author")
+	}
+}
+
+func TestParseArgs_CommitPassthrough(t *testing.T) {
+	opts, err := parseArgs([]string{"-nSABCD1234", "--author", "Jane Doe <jane@example.com>", "--date=yesterday", "--allow-empty", "--cleanup", "scissors"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.noVerify || !opts.sign || opts.signKey != "ABCD1234" {
+		t.Errorf("expected no-verify and signing with ABCD1234, got %+v", opts)
+	}
+	if opts.author != "Jane Doe <jane@example.com>" || opts.date != "yesterday" || !opts.allowEmpty || opts.cleanup != "scissors" {
+		t.Errorf("unexpected passthrough options: %+v", opts)
+	}
+
+	opts, err = parseArgs([]string{"--gpg-sign", "-S"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.sign || opts.signKey != "" {
+		t.Errorf("expected signing with the default key, got %+v", opts)
+	}
+	if _, err := parseArgs([]string{"--author="}); err == nil {
+		t.Error("expected error for empty --author")
+	}
+}
+
+func TestParseArgs_ContextSources(t *testing.T) {
+	opts, err := parseArgs([]string{"--context-file", "-", "--context-from-notes", "review"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.contextFile != "-" || opts.contextNotes != "review" {
+		t.Errorf("expected stdin context and review notes, got %q and %q", opts.contextFile, opts.contextNotes)
+	}
+	if _, err := parseArgs([]string{"--context-from-notes="}); err == nil {
+		t.Error("expected error for empty --context-from-notes")
+	}
+	for _, flag := range []string{"--edit", "--diff", "-d"} {
+		if _, err := parseArgs([]string{"--context-file", "-", flag}); err == nil {
+			t.Errorf("expected error for --context-file - with %s", flag)
+		}
+	}
+}
+
+func TestParseArgs_Language(t *testing.T) {
+	opts, err := parseArgs([]string{"--language=ja"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.language != "ja" {
+		t.Errorf("expected language ja, got %q", opts.language)
+	}
+}
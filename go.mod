@@ -2,4 +2,4 @@ module git-ai-commit
 
 go 1.26
 
-require github.com/BurntSushi/toml v1.4.0 // indirect
+require github.com/BurntSushi/toml v1.4.0
@@ -3,30 +3,93 @@ package app
 import (
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"git-ai-commit/internal/config"
+	"git-ai-commit/internal/deps"
 	"git-ai-commit/internal/engine"
 	"git-ai-commit/internal/git"
+	"git-ai-commit/internal/glossary"
+	"git-ai-commit/internal/goapi"
+	"git-ai-commit/internal/issue"
+	"git-ai-commit/internal/language"
+	"git-ai-commit/internal/message"
 	"git-ai-commit/internal/prompt"
+	"git-ai-commit/internal/redact"
+	"git-ai-commit/internal/rules"
+	"git-ai-commit/internal/scope"
 )
 
-func Run(context, contextFile, promptName, promptFile, engineName string, amend, addAll, edit, showDiff bool, includeFiles, excludeFiles []string, debugPrompt, debugCommand bool) (err error) {
+// Options holds the command-line settings for a Run.
+type Options struct {
+	Context      string // extra context for the engine
+	ContextFile  string // file with extra context, "-" for stdin
+	ContextNotes string // notes ref whose note on HEAD is added to the context
+	Prompt       string // prompt preset overriding the config
+	PromptFile   string // prompt file overriding the config
+	Engine       string // engine overriding the config
+	Language     string // message language overriding the config
+	Amend        bool
+	AddAll       bool // stage modified and deleted files first
+	Edit         bool // open the editor on the generated message
+	ShowDiff     bool // show the diff in the editor; implies Edit
+	IncludeFiles []string
+	ExcludeFiles []string
+	DebugPrompt  bool
+	DebugCommand bool
+	NoRules      bool // always call the engine, even when a rule matches
+	Signoff      bool
+	CoAuthors    []string
+	Commit       git.CommitOptions
+}
+
+func Run(opts Options) (err error) {
 	cfg, err := config.Load()
+	if err != nil {
+		if opts.ContextFile == "-" && errors.Is(err, config.ErrTrustPrompt) {
+			return fmt.Errorf("%w; --context-file - reads stdin, so run git-ai-commit once without it to review and trust the config", err)
+		}
+		return err
+	}
+
+	edit := opts.Edit || opts.ShowDiff
+	commitOpts := commitOptions(cfg.Commit, opts.Commit)
+	commitOpts.Amend, commitOpts.Edit, commitOpts.Verbose = opts.Amend, edit, opts.ShowDiff
+	if err := commitOpts.Validate(); err != nil {
+		return err
+	}
+	settings, err := git.ReadCommitSettings()
+	if err != nil {
+		return err
+	}
+	tmpl, err := promptTemplate(cfg)
 	if err != nil {
 		return err
 	}
 
-	contextText, err := loadContext(context, contextFile)
+	contextText, err := loadContext(opts.Context, opts.ContextFile, os.Stdin)
 	if err != nil {
 		return err
 	}
+	if opts.ContextNotes != "" {
+		note, err := headNote(opts.ContextNotes)
+		if err != nil {
+			return err
+		}
+		contextText = strings.TrimSpace(contextText + "\n\n" + note)
+	}
 
 	var restoreIndex func()
-	if addAll || len(includeFiles) > 0 {
-		restoreIndex, err = stageChanges(addAll, includeFiles)
+	if opts.AddAll || len(opts.IncludeFiles) > 0 {
+		restoreIndex, err = stageChanges(opts.AddAll, opts.IncludeFiles)
 		if err != nil {
 			return err
 		}
@@ -37,7 +100,7 @@ func Run(context, contextFile, promptName, promptFile, engineName string, amend,
 		}()
 	}
 
-	if amend {
+	if opts.Amend {
 		hasHead, err := git.HasHeadCommit()
 		if err != nil {
 			return err
@@ -47,62 +110,255 @@ func Run(context, contextFile, promptName, promptFile, engineName string, amend,
 		}
 	}
 
-	diff, filterResult, err := commitDiff(amend, cfg, excludeFiles)
+	diff, filterResult, err := commitDiff(opts.Amend, cfg, opts.ExcludeFiles)
 	if err != nil {
 		return err
 	}
 	if strings.TrimSpace(diff) == "" {
-		if amend {
+		switch {
+		case commitOpts.AllowEmpty && strings.TrimSpace(contextText) != "":
+			// The context describes the empty commit.
+		case commitOpts.AllowEmpty:
+			return fmt.Errorf("--allow-empty without changes needs --context or --context-file to describe the commit")
+		case opts.Amend:
 			return fmt.Errorf("no changes found in the last commit")
+		default:
+			return fmt.Errorf("no staged changes to commit")
 		}
-		return fmt.Errorf("no staged changes to commit")
 	}
 
-	if engineName != "" {
-		cfg.DefaultEngine = engineName
+	diff, contextText, redactor, err := redactInputs(cfg.Redact, diff, contextText)
+	if err != nil {
+		return err
+	}
+	var redactions []redact.Finding
+	if redactor != nil {
+		redactions = redactor.Findings()
+	}
+	if len(redactions) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: redacted %d potential secret(s) (%s) before sending the diff to the engine\n", len(redactions), strings.Join(redact.Kinds(redactions), ", "))
+	}
+
+	if opts.Engine != "" {
+		cfg.DefaultEngine = opts.Engine
+	}
+	if opts.Language != "" {
+		cfg.Language = opts.Language
 	}
 
 	// Apply CLI prompt overrides
-	if err := config.ApplyCLIPrompt(&cfg, promptName, promptFile); err != nil {
+	if err := config.ApplyCLIPrompt(&cfg, opts.Prompt, opts.PromptFile); err != nil {
+		return err
+	}
+
+	stats, err := changedFiles(opts.Amend, cfg.Policy)
+	if err != nil {
+		return err
+	}
+
+	changes, err := goChanges(opts.Amend, stats)
+	if err != nil {
+		return err
+	}
+	breaking, err := breakingChanges(opts.Amend, stats, changes)
+	if err != nil {
+		return err
+	}
+	depChanges, depsOnly, err := dependencyChanges(opts.Amend, stats)
+	if err != nil {
+		return err
+	}
+	issueKeys, err := branchIssues(cfg.Issues)
+	if err != nil {
+		return err
+	}
+	trailers, err := commitTrailers(opts.Amend, cfg, issueKeys, opts.Signoff, opts.CoAuthors)
+	if err != nil {
 		return err
 	}
 
-	promptText := prompt.Build(cfg.ResolvedPrompt, contextText, diff)
-	eng, commandLine, err := selectEngine(cfg)
+	scopes, err := changeScopes(cfg.Scopes, stats)
 	if err != nil {
 		return err
 	}
-	if debugCommand {
+
+	// checkMessage applies the checks every message must pass, whether an
+	// engine or a rule wrote it.
+	checkMessage := func(message string) (string, error) {
+		if err := checkBreakingFooter(cfg.Breaking, breaking, message); err != nil {
+			return "", err
+		}
+		if err := checkScopes(cfg.Prompt, scopes, message); err != nil {
+			return "", err
+		}
+		return applyGlossary(cfg.Glossary, message), nil
+	}
+
+	if !opts.NoRules {
+		msg, rule, err := matchRule(opts.Amend, cfg, stats, diff, depChanges, depsOnly, scopes)
+		if err != nil {
+			return err
+		}
+		if rule != "" {
+			// A rule message that fails a check, e.g. one in English when
+			// another language is configured, leaves the change to the engine.
+			msg, err = checkMessage(msg)
+			if err == nil {
+				err = checkLanguage(cfg.Language, cfg.SubjectLang, msg)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rule %q did not apply: %v\n", rule, err)
+				rule = ""
+			}
+		}
+		if rule != "" {
+			fmt.Fprintf(os.Stderr, "rule %q generated the message without calling an engine (use --no-rules to bypass)\n", rule)
+			msg, err = finishMessage(msg, cfg.Issues, issueKeys, trailers)
+			if err != nil {
+				return err
+			}
+			return git.CommitWithMessage(msg, editorOptions(commitOpts, settings, msg, fmt.Sprintf("rule %q", rule), filterResult, stats), nil)
+		}
+	}
+
+	sections, err := contextSections(cfg.Context.Providers, cfg.Policy.NeverSend)
+	if err != nil {
+		return err
+	}
+	sectionRedactions, err := redactSections(cfg.Redact, redactor, sections)
+	if err != nil {
+		return err
+	}
+	if len(sectionRedactions) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: redacted %d potential secret(s) (%s) from context provider output\n", len(sectionRedactions), strings.Join(redact.Kinds(sectionRedactions), ", "))
+		redactions = redactor.Findings()
+	}
+
+	examples, err := styleExamples(opts.Amend, cfg, stats)
+	if err != nil {
+		return err
+	}
+	history, err := relatedHistory(opts.Amend, cfg.Context, stats)
+	if err != nil {
+		return err
+	}
+
+	promptData := prompt.PromptData{
+		SystemPrompt: cfg.ResolvedPrompt,
+		Context:      contextText,
+		Sections:     sections,
+		Diff:         diff,
+		Files:        fileChanges(stats, filterResult),
+		Declarations: declarations(changes, filterResult),
+		Breaking:     breaking,
+		Dependencies: dependencyFacts(depChanges),
+		Scopes:       scopes,
+		Examples:     examples,
+		Template:     settings.Template,
+		CommentChar:  settings.CommentCharFor(""), // "auto" resolves to "#"
+		Glossary:     promptGlossary(cfg.Glossary),
+		Language:     languageLabel(cfg.Language),
+		SubjectLang:  languageLabel(cfg.SubjectLang),
+		History:      history,
+		Issues:       issueKeys,
+		IssuesAdded:  cfg.Issues.Placement != issue.PlacementNone,
+	}
+	if cfg.ResolvedTmpl != "" {
+		if err := addTemplateFields(&promptData, opts.Amend, stats, filterResult); err != nil {
+			return err
+		}
+	}
+	promptText, err := renderPrompt(tmpl, promptData)
+	if err != nil {
+		return err
+	}
+	eng, commandLine, err := selectEngine(cfg, promptData)
+	if err != nil {
+		return err
+	}
+	if opts.DebugCommand {
 		fmt.Fprintf(os.Stderr, "engine command: %s\n", commandLine)
 	}
-	if debugPrompt {
+	if opts.DebugPrompt && len(redactions) > 0 {
+		fmt.Fprintln(os.Stderr, "redactions:")
+		fmt.Fprint(os.Stderr, redact.FormatReport(redactions))
+	}
+	if opts.DebugPrompt && cfg.DetectedPrompt != "" {
+		fmt.Fprintf(os.Stderr, "prompt preset detected from history: %s\n", cfg.DetectedPrompt)
+	}
+	if opts.DebugPrompt {
 		fmt.Fprintln(os.Stderr, "prompt:")
 		fmt.Fprintln(os.Stderr, promptText)
 	}
 
-	output, err := eng.Generate(promptText)
-	if err != nil {
-		return buildEngineFailureError(err, filterResult, excludeFiles)
+	generate := func(promptText string) (string, error) {
+		output, err := eng.Generate(promptText)
+		if err != nil {
+			return "", buildEngineFailureError(err, filterResult, opts.ExcludeFiles)
+		}
+		message := sanitizeMessage(output)
+		if message == "" {
+			return "", fmt.Errorf("empty commit message from engine")
+		}
+		message, err = checkMessage(message)
+		if err != nil {
+			return "", err
+		}
+		// The builtin engine only writes English. Mixed-language text can
+		// fool the script check, so a mismatch only warns.
+		if cfg.DefaultEngine != engine.BuiltinName {
+			if err := checkLanguage(cfg.Language, cfg.SubjectLang, message); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}
+		return finishMessage(message, cfg.Issues, issueKeys, trailers)
 	}
-	message := sanitizeMessage(output)
-	if message == "" {
-		return fmt.Errorf("empty commit message from engine")
+	message, err := generate(promptText)
+	if err != nil {
+		return err
 	}
 
-	if showDiff {
-		edit = true
+	// Directives left in the editor re-prompt the engine with the edited
+	// message and the instructions.
+	revise := func(previous string, instructions []string) (string, error) {
+		data := promptData
+		data.Previous = strings.TrimSpace(previous)
+		for _, instruction := range instructions {
+			if instruction != "" {
+				data.Revisions = append(data.Revisions, instruction)
+			}
+		}
+		promptText, err := renderPrompt(tmpl, data)
+		if err != nil {
+			return "", err
+		}
+		if opts.DebugPrompt {
+			fmt.Fprintln(os.Stderr, "prompt:")
+			fmt.Fprintln(os.Stderr, promptText)
+		}
+		return generate(promptText)
 	}
-	if err := git.CommitWithMessage(message, amend, edit, showDiff); err != nil {
+
+	commitOpts = editorOptions(commitOpts, settings, message, fmt.Sprintf("engine %q", cfg.DefaultEngine), filterResult, stats)
+	if err := git.CommitWithMessage(message, commitOpts, revise); err != nil {
 		return err
 	}
 	return nil
 }
 
-func loadContext(context, contextFile string) (string, error) {
+// loadContext combines the --context text with the contents of the
+// --context-file, which is read from stdin when it is "-".
+func loadContext(context, contextFile string, stdin io.Reader) (string, error) {
 	if contextFile == "" {
 		return strings.TrimSpace(context), nil
 	}
-	data, err := os.ReadFile(contextFile)
+	var data []byte
+	var err error
+	if contextFile == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(contextFile)
+	}
 	if err != nil {
 		return "", fmt.Errorf("read context file: %w", err)
 	}
@@ -111,10 +367,108 @@ func loadContext(context, contextFile string) (string, error) {
 	return combined, nil
 }
 
-func selectEngine(cfg config.Config) (engine.Engine, string, error) {
+// headNote returns the note attached to HEAD in the notes ref; when
+// amending, HEAD is the commit being reworded. A missing note only warns.
+func headNote(ref string) (string, error) {
+	hasHead, err := git.HasHeadCommit()
+	if err != nil {
+		return "", err
+	}
+	note := ""
+	if hasHead {
+		if note, err = git.Note(ref, "HEAD"); err != nil {
+			return "", err
+		}
+	}
+	if note == "" {
+		fmt.Fprintf(os.Stderr, "warning: no note for HEAD in notes ref %q\n", ref)
+	}
+	return note, nil
+}
+
+// promptTemplate parses the prompt_template file, or returns the default
+// template when none is set. A broken template is an error rather than a
+// reason to fall back to the default.
+func promptTemplate(cfg config.Config) (*prompt.Template, error) {
+	if cfg.ResolvedTmpl == "" {
+		return prompt.Default(), nil
+	}
+	tmpl, err := prompt.Parse(cfg.PromptTmpl, cfg.ResolvedTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt_template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderPrompt executes the prompt template with data.
+func renderPrompt(tmpl *prompt.Template, data prompt.PromptData) (string, error) {
+	text, err := tmpl.Execute(data)
+	if err != nil {
+		return "", fmt.Errorf("render prompt: %w", err)
+	}
+	return text, nil
+}
+
+// addTemplateFields fills in the prompt data that only prompt_template
+// files use, so the default prompt does not pay for the extra git calls.
+func addTemplateFields(data *prompt.PromptData, amend bool, stats []git.FileStat, result git.Result) error {
+	branch, err := git.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	root, err := git.RepoRoot()
+	if err != nil {
+		return err
+	}
+	subjects, err := recentSubjects(amend)
+	if err != nil {
+		return err
+	}
+	data.Branch = branch
+	data.Repository = filepath.Base(root)
+	for _, st := range stats {
+		data.Paths = append(data.Paths, st.Path)
+	}
+	data.Diffstat = strings.TrimRight(diffstat(stats), "\n")
+	data.FilterNotice = filterNotice(result)
+	data.Subjects = subjects
+	return nil
+}
+
+// redactInputs replaces secrets in the diff and context before they leave the
+// machine. In block mode any finding aborts generation instead. The returned
+// Redactor, nil when redaction is off, keeps the placeholders for redacting
+// further prompt input.
+func redactInputs(cfg config.RedactConfig, diff, context string) (string, string, *redact.Redactor, error) {
+	if err := redact.ValidateMode(cfg.Mode); err != nil {
+		return "", "", nil, err
+	}
+	if cfg.Mode == redact.ModeOff {
+		return diff, context, nil, nil
+	}
+	r, err := redact.New(cfg.Patterns)
+	if err != nil {
+		return "", "", nil, err
+	}
+	diff = r.Redact(diff)
+	context = r.Redact(context)
+	findings := r.Findings()
+	if len(findings) > 0 && cfg.Mode == redact.ModeBlock {
+		return "", "", r, fmt.Errorf("staged changes contain %d potential secret(s) (%s); refusing to send them to the engine (redact.mode = %q)", len(findings), strings.Join(redact.Kinds(findings), ", "), redact.ModeBlock)
+	}
+	return diff, context, r, nil
+}
+
+// selectEngine returns the configured engine and its command line. data is
+// used by the builtin engine, which works from the change summary instead of
+// the prompt text.
+func selectEngine(cfg config.Config, data prompt.PromptData) (engine.Engine, string, error) {
 	name := strings.TrimSpace(cfg.DefaultEngine)
 	if name == "" {
-		return nil, "", fmt.Errorf("no engine configured")
+		return nil, "", fmt.Errorf("no engine configured: set engine in config or pass --engine, or use engine = %q to write messages offline without a model", engine.BuiltinName)
+	}
+	if name == engine.BuiltinName {
+		return engine.Builtin{Preset: cfg.Prompt, Data: data}, engine.BuiltinName, nil
 	}
 	if spec, ok := cfg.Engines[name]; ok {
 		return engine.CLI{Command: name, Args: spec.Args}, strings.Join(append([]string{name}, spec.Args...), " "), nil
@@ -150,13 +504,25 @@ func sanitizeMessage(message string) string {
 }
 
 func commitDiff(amend bool, cfg config.Config, excludeFiles []string) (string, git.Result, error) {
+	diffOpts := git.DiffOptions{
+		FunctionContext:   cfg.Diff.FunctionContext,
+		ContextLines:      cfg.Diff.ContextLines,
+		Algorithm:         cfg.Diff.Algorithm,
+		FindRenames:       cfg.Diff.Renames,
+		FindCopies:        cfg.Diff.Copies,
+		IgnoreSpaceChange: cfg.Diff.IgnoreSpaceChange,
+	}
 	var diff string
 	var err error
 	if amend {
-		diff, err = git.LastCommitDiff()
+		diff, err = git.LastCommitDiff(diffOpts)
 	} else {
-		diff, err = git.StagedDiff()
+		diff, err = git.StagedDiff(diffOpts)
+	}
+	if err != nil {
+		return "", git.Result{}, err
 	}
+	diff, err = enforceNeverSend(cfg.Policy, diff)
 	if err != nil {
 		return "", git.Result{}, err
 	}
@@ -174,31 +540,565 @@ func commitDiff(amend bool, cfg config.Config, excludeFiles []string) (string, g
 		maxLines = git.DefaultMaxFileLines
 	}
 
+	attrs, err := git.CheckAttr(git.DiffFiles(diff))
+	if err != nil {
+		return "", git.Result{}, err
+	}
+
+	headerPatterns := git.DefaultGeneratedHeaderPatterns()
+	if len(cfg.Filter.GeneratedHeaders) > 0 {
+		headerPatterns = cfg.Filter.GeneratedHeaders
+	}
+	headers, err := compileGeneratedHeaders(headerPatterns)
+	if err != nil {
+		return "", git.Result{}, err
+	}
+	var heads map[string]string
+	if len(headers) > 0 {
+		heads, err = git.FileHeads(git.DiffFiles(diff), amend, git.GeneratedHeaderLines)
+		if err != nil {
+			return "", git.Result{}, err
+		}
+	}
+
 	opts := git.Options{
-		MaxFileLines:    maxLines,
-		ExcludePatterns: patterns,
-		ExcludeFiles:    excludeFiles,
+		MaxFileLines:     maxLines,
+		ExcludePatterns:  patterns,
+		ExcludeFiles:     excludeFiles,
+		Attributes:       attrs,
+		FunctionContext:  cfg.Diff.FunctionContext,
+		GeneratedHeaders: headers,
+		Heads:            heads,
 	}
 	result := git.Filter(diff, opts)
 
-	if result.Truncated || len(result.ExcludedFiles) > 0 {
+	if result.Truncated || len(result.ExcludedFiles) > 0 || len(result.SummarizedFiles) > 0 {
 		return result.Diff + formatFilterNotice(result), result, nil
 	}
 	return result.Diff, result, nil
 }
 
+// enforceNeverSend applies policy.never_send to the raw diff. Matching files
+// either abort generation or are removed before filtering, so that they do not
+// appear in the filter notice either.
+func enforceNeverSend(policy config.PolicyConfig, diff string) (string, error) {
+	switch policy.OnMatch {
+	case "", config.PolicyAbort, config.PolicyStrip:
+	default:
+		return "", fmt.Errorf("invalid policy.on_match %q: must be %q or %q", policy.OnMatch, config.PolicyAbort, config.PolicyStrip)
+	}
+	stripped, matches := git.StripMatching(diff, policy.NeverSend)
+	if len(matches) == 0 {
+		return diff, nil
+	}
+	if policy.OnMatch == config.PolicyStrip {
+		if strings.TrimSpace(stripped) == "" {
+			return "", fmt.Errorf("policy.never_send: every staged file is protected; nothing can be sent to the engine")
+		}
+		return stripped, nil
+	}
+	var lines []string
+	for _, m := range matches {
+		lines = append(lines, fmt.Sprintf("  %s (matches %q)", m.Path, m.Pattern))
+	}
+	return "", fmt.Errorf("policy.never_send: refusing to send staged changes to any engine because these files are protected:\n%s\nUnstage them, or set policy.on_match = %q to leave them out of the prompt", strings.Join(lines, "\n"), config.PolicyStrip)
+}
+
+// changedFiles returns per-file statistics for the change being described,
+// leaving out files protected by policy.never_send.
+func changedFiles(amend bool, policy config.PolicyConfig) ([]git.FileStat, error) {
+	var stats []git.FileStat
+	var err error
+	if amend {
+		stats, err = git.LastCommitStat()
+	} else {
+		stats, err = git.StagedStat()
+	}
+	if err != nil {
+		return nil, err
+	}
+	kept := stats[:0]
+	for _, st := range stats {
+		if git.MatchesAnyPattern(st.Path, policy.NeverSend) || (st.OldPath != "" && git.MatchesAnyPattern(st.OldPath, policy.NeverSend)) {
+			continue
+		}
+		kept = append(kept, st)
+	}
+	return kept, nil
+}
+
+// fileChanges converts file statistics into the prompt's change summary,
+// noting how each file was filtered from the diff.
+func fileChanges(stats []git.FileStat, result git.Result) []prompt.FileChange {
+	notes := make(map[string]string)
+	for _, f := range result.TruncatedFiles {
+		notes[f] = "truncated in diff"
+	}
+	for _, f := range result.SummarizedFiles {
+		notes[f] = "summarized in diff"
+	}
+	for _, f := range result.ExcludedFiles {
+		notes[f] = "excluded from diff"
+	}
+	changes := make([]prompt.FileChange, 0, len(stats))
+	for _, st := range stats {
+		changes = append(changes, prompt.FileChange{
+			Path:    st.Path,
+			OldPath: st.OldPath,
+			Status:  st.Status,
+			Added:   st.Added,
+			Deleted: st.Deleted,
+			Binary:  st.Binary,
+			Filter:  notes[st.Path],
+		})
+	}
+	return changes
+}
+
+// maxDeclarations bounds the number of Go declaration changes added to the
+// prompt, so that large refactors do not crowd out the diff.
+const maxDeclarations = 100
+
+// goChanges compares the exported declarations of each changed Go file
+// before and after the change, including files excluded from the diff, whose
+// API breaks count all the same. Test files are skipped, as are files that
+// do not parse.
+func goChanges(amend bool, stats []git.FileStat) ([]goapi.Change, error) {
+	oldRev, newRev := revisions(amend)
+	var all []goapi.Change
+	for _, st := range stats {
+		if !strings.HasSuffix(st.Path, ".go") || strings.HasSuffix(st.Path, "_test.go") {
+			continue
+		}
+		oldPath := st.Path
+		if st.OldPath != "" {
+			oldPath = st.OldPath
+		}
+		oldSrc, _, err := git.FileAt(oldRev, oldPath)
+		if err != nil {
+			return nil, err
+		}
+		newSrc, _, err := git.FileAt(newRev, st.Path)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := goapi.Compare(st.Path, oldSrc, newSrc)
+		if err != nil {
+			continue
+		}
+		all = append(all, changes...)
+	}
+	return goapi.MergeMoves(all), nil
+}
+
+// revisions returns the revisions holding the old and new versions of the
+// files being described; an empty revision is the index.
+func revisions(amend bool) (string, string) {
+	if amend {
+		return "HEAD^", "HEAD"
+	}
+	return "HEAD", ""
+}
+
+// declarations converts Go declaration changes for the prompt, keeping at
+// most maxDeclarations. Files excluded from the diff are left out, as they
+// are from the diff itself.
+func declarations(changes []goapi.Change, result git.Result) []prompt.DeclChange {
+	var decls []prompt.DeclChange
+	for _, c := range changes {
+		if len(decls) == maxDeclarations {
+			break
+		}
+		if slices.Contains(result.ExcludedFiles, c.File) {
+			continue
+		}
+		d := prompt.DeclChange{
+			Package:   c.Package,
+			Kind:      c.Kind,
+			Name:      c.Name,
+			Action:    c.Action,
+			Signature: c.Signature,
+		}
+		if c.SignatureChanged() {
+			d.OldSignature = c.OldSignature
+		}
+		decls = append(decls, d)
+	}
+	return decls
+}
+
+// breakingChanges describes the changes that break code importing the
+// module: breaking changes to declarations in importable packages and a
+// changed module path in a go.mod file.
+func breakingChanges(amend bool, stats []git.FileStat, changes []goapi.Change) ([]string, error) {
+	var facts []string
+	for _, c := range changes {
+		if c.Breaking() && goapi.Importable(c.File, c.Package) {
+			facts = append(facts, c.Describe())
+		}
+	}
+	oldRev, newRev := revisions(amend)
+	for _, st := range stats {
+		if path.Base(st.Path) != "go.mod" {
+			continue
+		}
+		oldMod, oldOK, err := git.FileAt(oldRev, st.Path)
+		if err != nil {
+			return nil, err
+		}
+		newMod, newOK, err := git.FileAt(newRev, st.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !oldOK || !newOK {
+			continue
+		}
+		oldPath, newPath := goapi.ModulePath(oldMod), goapi.ModulePath(newMod)
+		if oldPath != newPath {
+			facts = append(facts, fmt.Sprintf("changed module path in %s from %q to %q", st.Path, oldPath, newPath))
+		}
+	}
+	return facts, nil
+}
+
+// dependencyChanges compares the dependency manifests among the changed
+// files. depsOnly reports whether the change consists solely of dependency
+// changes to manifests and their lock files.
+func dependencyChanges(amend bool, stats []git.FileStat) (changes []deps.Change, depsOnly bool, err error) {
+	oldRev, newRev := revisions(amend)
+	depsOnly = len(stats) > 0
+	for _, st := range stats {
+		if deps.IsLockFile(st.Path) {
+			continue
+		}
+		if !deps.IsManifest(st.Path) || st.OldPath != "" {
+			depsOnly = false
+			continue
+		}
+		oldSrc, _, err := git.FileAt(oldRev, st.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		newSrc, _, err := git.FileAt(newRev, st.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		fileChanges, onlyDeps, err := deps.Compare(st.Path, oldSrc, newSrc)
+		if err != nil {
+			depsOnly = false
+			continue
+		}
+		if !onlyDeps {
+			depsOnly = false
+		}
+		changes = append(changes, fileChanges...)
+	}
+	return changes, depsOnly && len(changes) > 0, nil
+}
+
+// matchRule tries the enabled deterministic rules, returning the generated
+// message and the name of the rule that fired, or an empty rule name.
+func matchRule(amend bool, cfg config.Config, stats []git.FileStat, diff string, depChanges []deps.Change, depsOnly bool, scopes []string) (string, string, error) {
+	if err := rules.Validate(cfg.Rules); err != nil {
+		return "", "", err
+	}
+	whitespaceOnly, err := git.WhitespaceOnly(amend)
+	if err != nil {
+		return "", "", err
+	}
+	msg, rule, _ := rules.Match(rules.Input{
+		Preset:         cfg.Prompt,
+		Files:          stats,
+		Diff:           diff,
+		WhitespaceOnly: whitespaceOnly,
+		Deps:           depChanges,
+		DepsOnly:       depsOnly,
+		Scopes:         scopes,
+	}, cfg.Rules)
+	return msg, rule, nil
+}
+
+// changeScopes returns the scopes of the changed files according to the
+// scopes settings, or nil when none are configured.
+func changeScopes(cfg config.ScopesConfig, stats []git.FileStat) ([]string, error) {
+	if err := scope.ValidateDerive(cfg.Derive); err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(stats))
+	for _, st := range stats {
+		files = append(files, st.Path)
+	}
+	return scope.Infer(files, cfg.Paths, cfg.Derive), nil
+}
+
+// commitOptions fills in options not given on the command line from the
+// [commit] defaults.
+func commitOptions(cfg config.CommitConfig, cli git.CommitOptions) git.CommitOptions {
+	opts := cli
+	opts.Sign = cli.Sign || cfg.Sign
+	if opts.SignKey == "" {
+		opts.SignKey = cfg.SignKey
+	}
+	opts.NoVerify = cli.NoVerify || cfg.NoVerify
+	if opts.Author == "" {
+		opts.Author = cfg.Author
+	}
+	if opts.Cleanup == "" {
+		opts.Cleanup = cfg.Cleanup
+	}
+	return opts
+}
+
+// editorOptions applies commit.cleanup and, when the message is edited, adds
+// a comment block naming the message's source, the filtered files and the
+// diffstat, written with the comment character git will strip.
+func editorOptions(opts git.CommitOptions, settings git.CommitSettings, msg, source string, result git.Result, stats []git.FileStat) git.CommitOptions {
+	if opts.Cleanup == "" {
+		opts.Cleanup = settings.Cleanup
+	}
+	if opts.Edit {
+		opts.CommentChar = settings.CommentCharFor(msg)
+		opts.Comment = editorComment(source, result, stats)
+	}
+	return opts
+}
+
+// editorComment describes how a message was generated, for the editor.
+func editorComment(source string, result git.Result, stats []git.FileStat) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Message generated by git-ai-commit with %s.\n", source)
+	if len(result.ExcludedFiles) > 0 {
+		fmt.Fprintf(&b, "Excluded from the diff: %s\n", strings.Join(withReasons(result.ExcludedFiles, result.Reasons), ", "))
+	}
+	if len(result.SummarizedFiles) > 0 {
+		fmt.Fprintf(&b, "Summarized in the diff: %s\n", strings.Join(withReasons(result.SummarizedFiles, result.Reasons), ", "))
+	}
+	if len(result.TruncatedFiles) > 0 {
+		fmt.Fprintf(&b, "Truncated in the diff: %s\n", strings.Join(result.TruncatedFiles, ", "))
+	}
+	if len(stats) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n")
+	b.WriteString(diffstat(stats))
+	return b.String()
+}
+
+// diffstat lists the lines added and deleted per file, and in total.
+func diffstat(stats []git.FileStat) string {
+	if len(stats) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	width := 0
+	for _, st := range stats {
+		width = max(width, len(st.Path))
+	}
+	added, deleted := 0, 0
+	for _, st := range stats {
+		if st.Binary {
+			fmt.Fprintf(&b, " %-*s | binary\n", width, st.Path)
+			continue
+		}
+		fmt.Fprintf(&b, " %-*s | +%d -%d\n", width, st.Path, st.Added, st.Deleted)
+		added += st.Added
+		deleted += st.Deleted
+	}
+	fmt.Fprintf(&b, " %d file(s) changed, +%d -%d\n", len(stats), added, deleted)
+	return b.String()
+}
+
+// branchIssues returns the issue keys found in the name of the current
+// branch by issues.branch_patterns.
+func branchIssues(cfg config.IssuesConfig) ([]string, error) {
+	if err := issue.ValidatePlacement(cfg.Placement); err != nil {
+		return nil, err
+	}
+	if len(cfg.BranchPatterns) == 0 {
+		if cfg.Required {
+			return nil, fmt.Errorf("issues.required is set but issues.branch_patterns is empty")
+		}
+		return nil, nil
+	}
+	branch, err := git.CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+	return issuesFromBranch(cfg, branch)
+}
+
+// issuesFromBranch extracts issue keys from a branch name, which is empty
+// when HEAD is detached. A missing key is an error only if issues.required
+// is set.
+func issuesFromBranch(cfg config.IssuesConfig, branch string) ([]string, error) {
+	if branch == "" {
+		if cfg.Required {
+			return nil, fmt.Errorf("issues.required: HEAD is detached, so there is no branch name to read an issue key from")
+		}
+		fmt.Fprintln(os.Stderr, "warning: HEAD is detached; no issue key is added to the message")
+		return nil, nil
+	}
+	keys, err := issue.Extract(branch, cfg.BranchPatterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 && cfg.Required {
+		return nil, fmt.Errorf("issues.required: no issue key found in branch name %q", branch)
+	}
+	return keys, nil
+}
+
+// promptGlossary converts the glossary settings for the prompt, sorted by
+// term.
+func promptGlossary(cfg config.GlossaryConfig) prompt.Glossary {
+	var g prompt.Glossary
+	for _, avoid := range slices.Sorted(maps.Keys(cfg.Prefer)) {
+		g.Prefer = append(g.Prefer, prompt.Preference{Avoid: avoid, Use: cfg.Prefer[avoid]})
+	}
+	g.Forbidden = cfg.Forbidden
+	for _, term := range slices.Sorted(maps.Keys(cfg.Definitions)) {
+		g.Definitions = append(g.Definitions, prompt.Definition{Term: term, Meaning: cfg.Definitions[term]})
+	}
+	return g
+}
+
+// applyGlossary writes the preferred terms in place of avoided ones and
+// warns about forbidden terms left in a generated message. A Conventional
+// Commits header is left alone, since its type and scope are checked
+// separately.
+func applyGlossary(cfg config.GlossaryConfig, msg string) string {
+	header, rest := message.SplitHeader(msg)
+	rest, replaced := glossary.Prefer(rest, cfg.Prefer)
+	msg = header + rest
+	for _, r := range replaced {
+		fmt.Fprintf(os.Stderr, "glossary: replaced %q with %q\n", r.Avoid, r.Use)
+	}
+	if found := glossary.Forbidden(msg, cfg.Forbidden); len(found) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: the message uses forbidden glossary term(s): %s\n", strings.Join(found, ", "))
+	}
+	return msg
+}
+
+// languageLabel names a configured language for the prompt, or returns ""
+// when none is set.
+func languageLabel(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	return language.Lookup(value).String()
+}
+
+// checkLanguage verifies that a generated message is written in the
+// configured language, with its subject line in subjectLang when that is
+// set. A Conventional Commits header is not checked.
+func checkLanguage(lang, subjectLang, msg string) error {
+	_, rest := message.SplitHeader(msg)
+	parts := []struct{ name, lang, text string }{{"message", lang, rest}}
+	if subjectLang != "" {
+		subject, body, _ := strings.Cut(rest, "\n")
+		parts = []struct{ name, lang, text string }{{"subject line", subjectLang, subject}, {"body", lang, body}}
+	}
+	for _, p := range parts {
+		if p.lang == "" {
+			continue
+		}
+		if err := language.Check(p.text, language.Lookup(p.lang)); err != nil {
+			return fmt.Errorf("language: the %s is not predominantly in the requested language: %v", p.name, err)
+		}
+	}
+	return nil
+}
+
+// checkScopes verifies that the scopes in a Conventional Commits style
+// subject are among the allowed scopes of the change. The karma preset must
+// also use a scope.
+func checkScopes(preset string, allowed []string, msg string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	used, ok := message.HeaderScopes(msg)
+	if !ok {
+		return nil
+	}
+	if len(used) == 0 {
+		if preset == "karma" {
+			return fmt.Errorf("scopes: the generated message has no scope; expected one of %s\n\nGenerated message:\n%s", strings.Join(allowed, ", "), msg)
+		}
+		return nil
+	}
+	for _, s := range used {
+		if !slices.Contains(allowed, s) {
+			return fmt.Errorf("scopes: the generated message uses scope %q, but the staged change only allows %s\n\nGenerated message:\n%s", s, strings.Join(allowed, ", "), msg)
+		}
+	}
+	return nil
+}
+
+// dependencyFacts describes dependency changes for the prompt.
+func dependencyFacts(changes []deps.Change) []string {
+	var facts []string
+	for _, c := range changes {
+		facts = append(facts, fmt.Sprintf("%s (%s)", c.Describe(), c.Manifest))
+	}
+	return facts
+}
+
+// checkBreakingFooter enforces breaking.require_footer.
+func checkBreakingFooter(cfg config.BreakingConfig, breaking []string, msg string) error {
+	if !cfg.RequireFooter || len(breaking) == 0 || message.HasBreakingFooter(msg) {
+		return nil
+	}
+	return fmt.Errorf("breaking.require_footer: the change breaks compatibility but the generated message has no BREAKING CHANGE footer:\n  %s\n\nGenerated message:\n%s", strings.Join(breaking, "\n  "), msg)
+}
+
+func compileGeneratedHeaders(patterns []string) ([]*regexp.Regexp, error) {
+	headers := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid generated header pattern %q: %w", pattern, err)
+		}
+		headers = append(headers, re)
+	}
+	return headers, nil
+}
+
 func formatFilterNotice(result git.Result) string {
+	notice := filterNotice(result)
+	if notice == "" {
+		return ""
+	}
+	return "\n\n[Filter notice: " + notice + "]"
+}
+
+// filterNotice lists the files excluded, summarized or truncated in the
+// diff, or returns "" when the diff is complete.
+func filterNotice(result git.Result) string {
 	var parts []string
 	if len(result.ExcludedFiles) > 0 {
-		parts = append(parts, fmt.Sprintf("Excluded files: %s", strings.Join(result.ExcludedFiles, ", ")))
+		parts = append(parts, fmt.Sprintf("Excluded files: %s", strings.Join(withReasons(result.ExcludedFiles, result.Reasons), ", ")))
+	}
+	if len(result.SummarizedFiles) > 0 {
+		parts = append(parts, fmt.Sprintf("Summarized files: %s", strings.Join(withReasons(result.SummarizedFiles, result.Reasons), ", ")))
 	}
 	if len(result.TruncatedFiles) > 0 {
 		parts = append(parts, fmt.Sprintf("Truncated files: %s", strings.Join(result.TruncatedFiles, ", ")))
 	}
-	if len(parts) == 0 {
-		return ""
+	return strings.Join(parts, "; ")
+}
+
+// withReasons annotates file names with the reason they were filtered, when
+// one was recorded.
+func withReasons(files []string, reasons map[string]string) []string {
+	out := make([]string, len(files))
+	for i, f := range files {
+		if reason := reasons[f]; reason != "" {
+			out[i] = fmt.Sprintf("%s (%s)", f, reason)
+		} else {
+			out[i] = f
+		}
 	}
-	return "\n\n[Filter notice: " + strings.Join(parts, "; ") + "]"
+	return out
 }
 
 // buildEngineFailureError converts an engine error into an actionable user
@@ -215,6 +1115,11 @@ func buildEngineFailureError(err error, filterResult git.Result, userExcluded []
 	var msg strings.Builder
 	msg.WriteString(engineErr.Error())
 
+	if errors.Is(engineErr.Err, exec.ErrNotFound) {
+		fmt.Fprintf(&msg, "\nHint: the engine command was not found. Install it, or use engine = %q to write messages offline without a model.", engine.BuiltinName)
+		return errors.New(msg.String())
+	}
+
 	logPath := writeTempLog(engineErr.Stderr)
 	if logPath != "" {
 		msg.WriteString("\nFull engine output saved to: ")
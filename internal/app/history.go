@@ -0,0 +1,245 @@
+package app
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"git-ai-commit/internal/config"
+	"git-ai-commit/internal/git"
+	"git-ai-commit/internal/prompt"
+)
+
+// historyOversample is how many commits are read per requested example, to
+// leave enough after skipping bots and reverts.
+const historyOversample = 5
+
+// maxExampleLines bounds the length of each style example.
+const maxExampleLines = 15
+
+// botNames lists authors whose commits are skipped unless
+// history.include_bots is set, in addition to any "[bot]" account.
+var botNames = []string{"dependabot", "renovate", "github-actions", "greenkeeper", "snyk-bot"}
+
+// defaultRelatedHistoryBytes bounds the related history section when
+// context.related_history_max_bytes is not set.
+const defaultRelatedHistoryBytes = 2048
+
+// issuePattern matches issue references such as "#12", "GH-12" or "ABC-123".
+var issuePattern = regexp.MustCompile(`(?:^|[\s(\[])(#\d+|[A-Z][A-Z0-9]+-\d+)\b`)
+
+// notIssuePrefixes lists prefixes of names like "UTF-8" and "SHA-256" that
+// look like tracker keys but are not.
+var notIssuePrefixes = []string{"AES", "ISO", "MD", "RFC", "RSA", "SHA", "UTF"}
+
+// recentSubjectCount is the number of recent subjects given to prompt
+// templates.
+const recentSubjectCount = 10
+
+// recentSubjects returns the subjects of the latest commits, newest first,
+// skipping the commit being amended.
+func recentSubjects(amend bool) ([]string, error) {
+	hasHead, err := git.HasHeadCommit()
+	if err != nil || !hasHead {
+		return nil, err
+	}
+	commits, err := git.RecentCommits("HEAD", recentSubjectCount+1, nil)
+	if err != nil {
+		return nil, err
+	}
+	skip := ""
+	if amend {
+		head, err := git.RecentCommits("HEAD", 1, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range head {
+			skip = c.Hash
+		}
+	}
+	var subjects []string
+	for _, c := range commits {
+		if len(subjects) == recentSubjectCount {
+			break
+		}
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		if c.Hash == skip || subject == "" {
+			continue
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, nil
+}
+
+// styleExamples returns up to cfg.Examples recent commit messages to show as
+// style examples. When history.same_paths is set, commits touching the
+// staged paths are preferred, topped up from the whole history.
+func styleExamples(amend bool, cfg config.Config, stats []git.FileStat) ([]string, error) {
+	if cfg.Examples <= 0 {
+		return nil, nil
+	}
+	hasHead, err := git.HasHeadCommit()
+	if err != nil || !hasHead {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	if amend {
+		// Skip the commit being amended.
+		head, err := git.RecentCommits("HEAD", 1, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range head {
+			seen[c.Hash] = true
+		}
+	}
+
+	var pathSets [][]string
+	if cfg.History.SamePaths && len(stats) > 0 {
+		var paths []string
+		for _, st := range stats {
+			paths = append(paths, st.Path)
+		}
+		pathSets = append(pathSets, paths)
+	}
+	pathSets = append(pathSets, nil)
+
+	var examples []string
+	for _, paths := range pathSets {
+		commits, err := git.RecentCommits("HEAD", cfg.Examples*historyOversample+1, paths)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range commits {
+			if len(examples) == cfg.Examples {
+				return examples, nil
+			}
+			if seen[c.Hash] || c.Message == "" {
+				continue
+			}
+			seen[c.Hash] = true
+			if !cfg.History.IncludeBots && isBotCommit(c) {
+				continue
+			}
+			if !cfg.History.IncludeReverts && isRevertCommit(c) {
+				continue
+			}
+			examples = append(examples, truncateLines(c.Message, maxExampleLines))
+		}
+	}
+	return examples, nil
+}
+
+// relatedHistory returns the subjects of the last cfg.RelatedHistory
+// commits touching each changed file, with the issues they reference. Files
+// are added in order until the size limit is reached; files without history
+// are left out.
+func relatedHistory(amend bool, cfg config.ContextConfig, stats []git.FileStat) ([]prompt.FileHistory, error) {
+	if cfg.RelatedHistory <= 0 || len(stats) == 0 {
+		return nil, nil
+	}
+	hasHead, err := git.HasHeadCommit()
+	if err != nil || !hasHead {
+		return nil, err
+	}
+	var amended string
+	if amend {
+		// Skip the commit being amended.
+		head, err := git.RecentCommits("HEAD", 1, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(head) > 0 {
+			amended = head[0].Hash
+		}
+	}
+	budget := cfg.RelatedHistoryMaxBytes
+	if budget <= 0 {
+		budget = defaultRelatedHistoryBytes
+	}
+
+	var history []prompt.FileHistory
+	for _, st := range stats {
+		paths := []string{st.Path}
+		if st.OldPath != "" {
+			paths = append(paths, st.OldPath)
+		}
+		commits, err := git.RecentCommits("HEAD", cfg.RelatedHistory+1, paths)
+		if err != nil {
+			return nil, err
+		}
+		entry := prompt.FileHistory{Path: st.Path}
+		size := len(entry.Path) + 2
+		for _, c := range commits {
+			if c.Hash == amended || len(entry.Subjects) == cfg.RelatedHistory {
+				continue
+			}
+			subject, _, _ := strings.Cut(c.Message, "\n")
+			if size+len(subject)+3 > budget {
+				break
+			}
+			size += len(subject) + 3
+			entry.Subjects = append(entry.Subjects, subject)
+			for _, ref := range issueRefs(c.Message) {
+				if !slices.Contains(entry.Issues, ref) {
+					entry.Issues = append(entry.Issues, ref)
+				}
+			}
+		}
+		if len(entry.Subjects) == 0 {
+			continue
+		}
+		if n := len(strings.Join(entry.Issues, ", ")) + 11; len(entry.Issues) > 0 && size+n <= budget {
+			size += n
+		} else {
+			entry.Issues = nil
+		}
+		history = append(history, entry)
+		if budget -= size; budget <= 0 {
+			break
+		}
+	}
+	return history, nil
+}
+
+// issueRefs returns the issue references in a commit message.
+func issueRefs(msg string) []string {
+	var refs []string
+	for _, m := range issuePattern.FindAllStringSubmatch(msg, -1) {
+		prefix, _, _ := strings.Cut(m[1], "-")
+		if slices.Contains(notIssuePrefixes, prefix) {
+			continue
+		}
+		refs = append(refs, m[1])
+	}
+	return refs
+}
+
+// isBotCommit reports whether a commit was authored by a bot account.
+func isBotCommit(c git.Commit) bool {
+	name, email := strings.ToLower(c.Author), strings.ToLower(c.Email)
+	if strings.Contains(name, "[bot]") || strings.Contains(email, "[bot]") {
+		return true
+	}
+	for _, bot := range botNames {
+		if strings.HasPrefix(name, bot) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRevertCommit reports whether a commit reverts another, as written by
+// git revert.
+func isRevertCommit(c git.Commit) bool {
+	return strings.HasPrefix(c.Message, "Revert \"") || strings.Contains(c.Message, "This reverts commit ")
+}
+
+// truncateLines keeps the first maxLines lines of s.
+func truncateLines(s string, maxLines int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) <= maxLines {
+		return s
+	}
+	return strings.Join(lines[:maxLines], "\n") + "\n[...]"
+}
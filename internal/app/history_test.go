@@ -0,0 +1,59 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"git-ai-commit/internal/config"
+	"git-ai-commit/internal/git"
+)
+
+func TestIsBotCommit(t *testing.T) {
+	tests := []struct {
+		commit git.Commit
+		want   bool
+	}{
+		{git.Commit{Author: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com"}, true},
+		{git.Commit{Author: "renovate-bot", Email: "bot@renovateapp.com"}, true},
+		{git.Commit{Author: "github-actions", Email: "actions@github.com"}, true},
+		{git.Commit{Author: "Jane Doe", Email: "jane@example.com"}, false},
+	}
+	for _, tt := range tests {
+		if got := isBotCommit(tt.commit); got != tt.want {
+			t.Errorf("isBotCommit(%+v) = %v, want %v", tt.commit, got, tt.want)
+		}
+	}
+}
+
+func TestIsRevertCommit(t *testing.T) {
+	if !isRevertCommit(git.Commit{Message: "Revert \"Add a\"\n\nThis reverts commit abc123."}) {
+		t.Error("expected git revert message to be a revert")
+	}
+	if isRevertCommit(git.Commit{Message: "Fix revert button styling"}) {
+		t.Error("unexpected revert for ordinary message")
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	if got := truncateLines("a\nb\nc", 2); got != "a\nb\n[...]" {
+		t.Fatalf("truncateLines = %q", got)
+	}
+	if got := truncateLines("a\nb", 2); got != "a\nb" {
+		t.Fatalf("truncateLines = %q", got)
+	}
+}
+
+func TestStyleExamplesDisabled(t *testing.T) {
+	examples, err := styleExamples(false, config.Config{}, nil)
+	if err != nil || examples != nil {
+		t.Fatalf("styleExamples = %v, %v, want none", examples, err)
+	}
+}
+
+func TestIssueRefs(t *testing.T) {
+	got := issueRefs("Fix crash on save (#12)\n\nSee GH-7 and PROJ-123.\nFixes #12\nNot an issue: UTF-8, abc#3")
+	want := []string{"#12", "GH-7", "PROJ-123", "#12"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("issueRefs = %v, want %v", got, want)
+	}
+}
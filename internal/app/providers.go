@@ -0,0 +1,221 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"git-ai-commit/internal/config"
+	"git-ai-commit/internal/git"
+	"git-ai-commit/internal/prompt"
+	"git-ai-commit/internal/redact"
+)
+
+// defaultProviderTimeout and defaultProviderBytes apply to context
+// providers that set no timeout or max_bytes.
+const (
+	defaultProviderTimeout = 10 * time.Second
+	defaultProviderBytes   = 4096
+)
+
+// contextSections gathers the output of the context providers, run from the
+// repository root. A command that fails or times out only warns, so that a
+// broken script does not block the commit; output of a failed command is
+// still used, since failing tests are worth describing. Files matching
+// neverSend are left out.
+func contextSections(providers []config.ContextProvider, neverSend []string) ([]prompt.ContextSection, error) {
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	timeouts := make([]time.Duration, len(providers))
+	for i, p := range providers {
+		timeout, err := validateProvider(p)
+		if err != nil {
+			return nil, err
+		}
+		timeouts[i] = timeout
+	}
+	root, err := git.RepoRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []prompt.ContextSection
+	for i, p := range providers {
+		var text string
+		if p.Command != "" {
+			text, err = providerCommandOutput(root, p.Command, timeouts[i])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: context provider %q: %v\n", p.Name, err)
+			}
+		} else {
+			text, err = providerFiles(root, p.Files, p.FromRepo, neverSend)
+			if err != nil {
+				return nil, fmt.Errorf("context provider %q: %w", p.Name, err)
+			}
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		maxBytes := p.MaxBytes
+		if maxBytes == 0 {
+			maxBytes = defaultProviderBytes
+		}
+		sections = append(sections, prompt.ContextSection{Name: p.Name, Text: truncateSection(text, maxBytes)})
+	}
+	return sections, nil
+}
+
+// validateProvider checks a provider's settings and returns its timeout.
+func validateProvider(p config.ContextProvider) (time.Duration, error) {
+	if strings.TrimSpace(p.Name) == "" {
+		return 0, fmt.Errorf("context provider without a name")
+	}
+	if (p.Command == "") == (p.Files == "") {
+		return 0, fmt.Errorf("context provider %q must set exactly one of command and files", p.Name)
+	}
+	if p.MaxBytes < 0 {
+		return 0, fmt.Errorf("context provider %q: max_bytes must not be negative", p.Name)
+	}
+	if p.Timeout == "" {
+		return defaultProviderTimeout, nil
+	}
+	timeout, err := time.ParseDuration(p.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("context provider %q: invalid timeout %q", p.Name, p.Timeout)
+	}
+	return timeout, nil
+}
+
+// providerCommandOutput runs command with sh and returns its standard
+// output. Output is returned along with a failed command's error, but not
+// after a timeout.
+func providerCommandOutput(root, command string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = root
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("%v: %s", err, msg)
+		}
+		return stdout.String(), err
+	}
+	return stdout.String(), nil
+}
+
+// providerFiles returns the contents of the files matching pattern, relative
+// to root. With several files, each is headed by its path. Files matching
+// neverSend, as policy.never_send, are skipped with a warning. When confined
+// is set, as for providers from the repo config, a file that resolves
+// outside root, e.g. through a symlink, is an error.
+func providerFiles(root, pattern string, confined bool, neverSend []string) (string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(root, pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid files pattern %q: %w", pattern, err)
+	}
+	var files, names []string
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		rel = filepath.ToSlash(rel)
+		if confined {
+			within, err := config.IsPathWithinRoot(path, root)
+			if err != nil {
+				return "", err
+			}
+			if !within {
+				return "", fmt.Errorf("%s resolves to a path outside the repository", rel)
+			}
+		}
+		if git.MatchesAnyPattern(rel, neverSend) {
+			fmt.Fprintf(os.Stderr, "warning: context provider file %s matches policy.never_send; leaving it out\n", rel)
+			continue
+		}
+		files = append(files, path)
+		names = append(names, rel)
+	}
+	var b strings.Builder
+	for i, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		if len(files) > 1 {
+			fmt.Fprintf(&b, "--- %s\n", names[i])
+		}
+		b.Write(bytes.TrimRight(data, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// truncateSection cuts text to at most maxBytes, at a line break if there
+// is one and otherwise between runes, and marks the cut.
+func truncateSection(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	cut := text[:end]
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "\n[...]"
+}
+
+// redactSections redacts secrets from provider output in place with r, the
+// Redactor used for the diff and context, so that a secret keeps its
+// placeholder across the prompt. It returns the findings in the sections. r
+// is nil when redaction is off.
+func redactSections(cfg config.RedactConfig, r *redact.Redactor, sections []prompt.ContextSection) ([]redact.Finding, error) {
+	if r == nil || len(sections) == 0 {
+		return nil, nil
+	}
+	before := make(map[string]int)
+	for _, f := range r.Findings() {
+		before[f.Placeholder] = f.Count
+	}
+	for i := range sections {
+		sections[i].Text = r.Redact(sections[i].Text)
+	}
+	var findings []redact.Finding
+	for _, f := range r.Findings() {
+		if f.Count > before[f.Placeholder] {
+			f.Count -= before[f.Placeholder]
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) > 0 && cfg.Mode == redact.ModeBlock {
+		return findings, fmt.Errorf("context provider output contains %d potential secret(s) (%s); refusing to send it to the engine (redact.mode = %q)", len(findings), strings.Join(redact.Kinds(findings), ", "), redact.ModeBlock)
+	}
+	return findings, nil
+}
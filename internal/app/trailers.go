@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"git-ai-commit/internal/config"
+	"git-ai-commit/internal/git"
+	"git-ai-commit/internal/issue"
+)
+
+// maxCoAuthorCandidates bounds the authors listed when a --co-author value
+// is ambiguous.
+const maxCoAuthorCandidates = 5
+
+// commitTrailers returns the trailers to add to the generated message, in
+// order: those of the commit being amended, static trailers, co-authors,
+// issue keys and the sign-off.
+func commitTrailers(amend bool, cfg config.Config, issueKeys []string, signoff bool, coAuthors []string) ([]string, error) {
+	var trailers []string
+	if amend {
+		msg, err := git.CommitMessage("HEAD")
+		if err != nil {
+			return nil, err
+		}
+		kept, err := git.ParseTrailers(msg)
+		if err != nil {
+			return nil, err
+		}
+		trailers = append(trailers, kept...)
+	}
+
+	for _, t := range cfg.Trailers.Static {
+		if key, value, ok := strings.Cut(t, ":"); !ok || strings.TrimSpace(key) == "" || strings.ContainsAny(strings.TrimSpace(key), " \t") || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("invalid trailer %q in trailers.static: must be \"Key: value\"", t)
+		}
+	}
+	trailers = append(trailers, cfg.Trailers.Static...)
+
+	if len(coAuthors) > 0 {
+		aliases, err := config.LoadCoAuthors()
+		if err != nil {
+			return nil, err
+		}
+		authors, err := git.Authors()
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range coAuthors {
+			author, err := resolveCoAuthor(value, aliases, authors)
+			if err != nil {
+				return nil, err
+			}
+			trailers = append(trailers, "Co-authored-by: "+author)
+		}
+	}
+
+	if cfg.Issues.Placement == "" || cfg.Issues.Placement == issue.PlacementTrailer {
+		trailers = append(trailers, issue.Trailers(issueKeys, cfg.Issues.Trailer)...)
+	}
+
+	if signoff || cfg.Trailers.Signoff {
+		ident, err := git.CommitterIdent()
+		if err != nil {
+			return nil, err
+		}
+		trailers = append(trailers, "Signed-off-by: "+ident)
+	}
+	return trailers, nil
+}
+
+// resolveCoAuthor turns a --co-author value into "Name <email>". The value
+// may already have that form, be an alias from co-authors.toml, or match
+// exactly one author in the history by name or email.
+func resolveCoAuthor(value string, aliases map[string]string, authors []string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "<") && strings.HasSuffix(value, ">") {
+		return value, nil
+	}
+	if author, ok := aliases[value]; ok {
+		return author, nil
+	}
+	needle := strings.ToLower(value)
+	var matches []string
+	for _, author := range authors {
+		if strings.Contains(strings.ToLower(author), needle) {
+			matches = append(matches, author)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("--co-author %q: no alias in co-authors.toml and no matching author in the history", value)
+	case 1:
+		return matches[0], nil
+	}
+	if len(matches) > maxCoAuthorCandidates {
+		matches = append(matches[:maxCoAuthorCandidates], "...")
+	}
+	return "", fmt.Errorf("--co-author %q matches several authors: %s", value, strings.Join(matches, ", "))
+}
+
+// finishMessage adds the issue prefix and the trailers to a generated
+// message.
+func finishMessage(msg string, cfg config.IssuesConfig, issueKeys, trailers []string) (string, error) {
+	if cfg.Placement == issue.PlacementPrefix {
+		msg = issue.Prefix(msg, issueKeys, cfg.PrefixFormat)
+	}
+	return git.AddTrailers(msg, trailers)
+}
@@ -8,21 +8,48 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+
+	"git-ai-commit/internal/convention"
 )
 
 type Config struct {
 	DefaultEngine string                  `toml:"engine"`
 	Prompt        string                  `toml:"prompt"`
 	PromptFile    string                  `toml:"prompt_file"`
+	PromptTmpl    string                  `toml:"prompt_template"` // Template file replacing the built-in prompt layout
 	Engines       map[string]EngineConfig `toml:"engines"`
 	Filter        FilterConfig            `toml:"filter"`
+	Redact        RedactConfig            `toml:"redact"`
+	Policy        PolicyConfig            `toml:"policy"`
+	Diff          DiffConfig              `toml:"diff"`
+	Breaking      BreakingConfig          `toml:"breaking"`
+	Rules         map[string]bool         `toml:"rules"`
+	Scopes        ScopesConfig            `toml:"scopes"`
+	History       HistoryConfig           `toml:"history"`
+	Examples      int                     `toml:"history_examples"` // Number of past commits shown as style examples
+	Context       ContextConfig           `toml:"context"`
+	Issues        IssuesConfig            `toml:"issues"`
+	Trailers      TrailersConfig          `toml:"trailers"`
+	Commit        CommitConfig            `toml:"commit"`
+	Glossary      GlossaryConfig          `toml:"glossary"`
+	Language      string                  `toml:"language"`         // Language of the message, e.g. "ja"
+	SubjectLang   string                  `toml:"subject_language"` // Language of the subject line when it differs
 
 	// ResolvedPrompt holds the final prompt text after loading from preset or file.
 	// This is not read from config files directly.
 	ResolvedPrompt string `toml:"-"`
+
+	// ResolvedTmpl holds the text of the prompt_template file, if one is set.
+	ResolvedTmpl string `toml:"-"`
+
+	// DetectedPrompt describes how the prompt preset was picked from the
+	// repository's history when no prompt was configured at any layer.
+	DetectedPrompt string `toml:"-"`
 }
 
 // FilterConfig holds diff filtering configuration.
@@ -30,15 +57,131 @@ type FilterConfig struct {
 	MaxFileLines           int      `toml:"max_file_lines"`           // Max lines per file (0 = use default)
 	DefaultExcludePatterns []string `toml:"default_exclude_patterns"` // Override built-in defaults
 	ExcludePatterns        []string `toml:"exclude_patterns"`         // Additional patterns to exclude
+	GeneratedHeaders       []string `toml:"generated_headers"`        // Override built-in generated-file header regexes
+}
+
+// DiffConfig holds options for generating the diff sent to the engine.
+type DiffConfig struct {
+	FunctionContext   bool   `toml:"function_context"`    // Show whole functions as hunk context
+	ContextLines      int    `toml:"context_lines"`       // Lines of context (0 = git default)
+	Algorithm         string `toml:"algorithm"`           // myers, minimal, patience or histogram
+	Renames           bool   `toml:"renames"`             // Detect renames (-M)
+	Copies            bool   `toml:"copies"`              // Detect copies (-C)
+	IgnoreSpaceChange bool   `toml:"ignore_space_change"` // Ignore changes in amount of whitespace
+}
+
+// BreakingConfig holds options for detected breaking API changes.
+type BreakingConfig struct {
+	RequireFooter bool `toml:"require_footer"` // Refuse messages without a BREAKING CHANGE footer when a break is detected
+}
+
+// ScopesConfig holds the canonical commit scopes for changed paths.
+type ScopesConfig struct {
+	Paths  map[string]string `toml:"paths"`  // Glob pattern -> scope name; the longest matching pattern wins
+	Derive string            `toml:"derive"` // Derive scopes for unmatched files: "package" or "directory"
+}
+
+// HistoryConfig selects the past commits used as style examples when
+// history_examples is set.
+type HistoryConfig struct {
+	SamePaths      bool `toml:"same_paths"`      // Prefer commits touching the staged paths
+	IncludeBots    bool `toml:"include_bots"`    // Include commits by bots such as dependabot[bot]
+	IncludeReverts bool `toml:"include_reverts"` // Include revert commits
+}
+
+// ContextConfig holds options for extra context gathered for the prompt.
+type ContextConfig struct {
+	RelatedHistory         int               `toml:"related_history"`           // Recent commit subjects listed per staged file (0 = off)
+	RelatedHistoryMaxBytes int               `toml:"related_history_max_bytes"` // Size limit for the related history (0 = default)
+	Providers              []ContextProvider `toml:"providers"`                 // Commands and files whose output is added to the prompt
+}
+
+// ContextProvider captures the output of a command, or the contents of
+// files, as a named section of the prompt.
+type ContextProvider struct {
+	Name     string `toml:"name"`      // Section name in the prompt
+	Command  string `toml:"command"`   // Shell command run from the repository root
+	Files    string `toml:"files"`     // Glob of files relative to the repository root; used without command
+	Timeout  string `toml:"timeout"`   // Command time limit, e.g. "30s" (default: 10s)
+	MaxBytes int    `toml:"max_bytes"` // Size limit for the section (default: 4096)
+
+	// FromRepo is set for providers from the repo config, whose files must
+	// resolve to paths within the repository.
+	FromRepo bool `toml:"-"`
 }
 
+// IssuesConfig holds options for issue keys read from the branch name.
+type IssuesConfig struct {
+	BranchPatterns []string `toml:"branch_patterns"` // Regexes finding issue keys in the branch name; the first group, if any, is the key
+	Placement      string   `toml:"placement"`       // trailer (default), prefix or none
+	Trailer        string   `toml:"trailer"`         // Trailer key (default: Refs)
+	PrefixFormat   string   `toml:"prefix_format"`   // Subject prefix with {{key}} replaced by the keys (default: [{{key}}])
+	Required       bool     `toml:"required"`        // Fail when no issue key is found
+}
+
+// TrailersConfig holds trailers added to every generated message.
+type TrailersConfig struct {
+	Static  []string `toml:"static"`  // "Key: value" trailers, accumulated across layers
+	Signoff bool     `toml:"signoff"` // Add a Signed-off-by trailer for the committer
+}
+
+// CommitConfig holds defaults for options passed through to git commit.
+type CommitConfig struct {
+	Sign     bool   `toml:"sign"`      // GPG/SSH-sign commits (-S)
+	SignKey  string `toml:"sign_key"`  // Key to sign with (-S<keyid>); implies sign
+	NoVerify bool   `toml:"no_verify"` // Skip the pre-commit and commit-msg hooks
+	Author   string `toml:"author"`    // Override the author, "Name <email>"
+	Cleanup  string `toml:"cleanup"`   // git commit --cleanup mode
+}
+
+// GlossaryConfig holds the project's terminology.
+type GlossaryConfig struct {
+	Prefer      map[string]string `toml:"prefer"`      // Avoided term to preferred term; replaced in generated messages
+	Forbidden   []string          `toml:"forbidden"`   // Terms flagged when they appear in a generated message
+	Definitions map[string]string `toml:"definitions"` // Project terms and what they mean
+}
+
+// RedactConfig holds secret redaction configuration.
+type RedactConfig struct {
+	Mode     string   `toml:"mode"`     // mask (default), block or off
+	Patterns []string `toml:"patterns"` // Additional secret regexes
+}
+
+// PolicyConfig holds rules about what may be sent to an engine.
+type PolicyConfig struct {
+	NeverSend []string `toml:"never_send"` // Glob patterns for files that must never reach an engine
+	OnMatch   string   `toml:"on_match"`   // abort (default) or strip
+}
+
+// Policy actions accepted by policy.on_match.
+const (
+	PolicyAbort = "abort"
+	PolicyStrip = "strip"
+)
+
 // rawConfig is the TOML structure used to detect mutual exclusivity in a single layer.
 type rawConfig struct {
 	DefaultEngine string                  `toml:"engine"`
 	Prompt        string                  `toml:"prompt"`
 	PromptFile    string                  `toml:"prompt_file"`
+	PromptTmpl    string                  `toml:"prompt_template"` // Template file replacing the built-in prompt layout
 	Engines       map[string]EngineConfig `toml:"engines"`
 	Filter        FilterConfig            `toml:"filter"`
+	Redact        RedactConfig            `toml:"redact"`
+	Policy        PolicyConfig            `toml:"policy"`
+	Diff          DiffConfig              `toml:"diff"`
+	Breaking      BreakingConfig          `toml:"breaking"`
+	Rules         map[string]bool         `toml:"rules"`
+	Scopes        ScopesConfig            `toml:"scopes"`
+	History       HistoryConfig           `toml:"history"`
+	Examples      int                     `toml:"history_examples"` // Number of past commits shown as style examples
+	Context       ContextConfig           `toml:"context"`
+	Issues        IssuesConfig            `toml:"issues"`
+	Trailers      TrailersConfig          `toml:"trailers"`
+	Commit        CommitConfig            `toml:"commit"`
+	Glossary      GlossaryConfig          `toml:"glossary"`
+	Language      string                  `toml:"language"`         // Language of the message, e.g. "ja"
+	SubjectLang   string                  `toml:"subject_language"` // Language of the subject line when it differs
 }
 
 type EngineConfig struct {
@@ -71,6 +214,7 @@ func Load() (Config, error) {
 	cfg := Default()
 	var promptFilePath string // resolved path used by resolvePromptFromPath
 	var promptFileRepoRoot string
+	var templatePath, templateRepoRoot string // the same for prompt_template
 
 	// Read all git config scopes in a single call.
 	gitScopes, err := readGitConfigScopes()
@@ -93,12 +237,14 @@ func Load() (Config, error) {
 		return cfg, err
 	}
 	promptFilePath, promptFileRepoRoot = gitScopePromptPaths(gitScopes.system, promptFilePath, promptFileRepoRoot, cfg)
+	templatePath, templateRepoRoot = gitScopeTemplatePaths(gitScopes.system, templatePath, templateRepoRoot, cfg)
 
 	// 2. Global git config
 	if err := applyGitConfigScope(&cfg, gitScopes.global, "user git config", homeDir); err != nil {
 		return cfg, err
 	}
 	promptFilePath, promptFileRepoRoot = gitScopePromptPaths(gitScopes.global, promptFilePath, promptFileRepoRoot, cfg)
+	templatePath, templateRepoRoot = gitScopeTemplatePaths(gitScopes.global, templatePath, templateRepoRoot, cfg)
 
 	// 3. User TOML config
 	userPath, err := configPath()
@@ -116,6 +262,10 @@ func Load() (Config, error) {
 			promptFilePath = ""
 			promptFileRepoRoot = ""
 		}
+		if cfg.PromptTmpl != "" {
+			templatePath = userPath
+			templateRepoRoot = ""
+		}
 	} else if !os.IsNotExist(err) {
 		return cfg, fmt.Errorf("read user config: %w", err)
 	}
@@ -128,7 +278,8 @@ func Load() (Config, error) {
 		}
 		if trusted {
 			var repoCfg rawConfig
-			if err := toml.Unmarshal(data, &repoCfg); err != nil {
+			md, err := toml.Decode(string(data), &repoCfg)
+			if err != nil {
 				return cfg, fmt.Errorf("parse repo config: %w", err)
 			}
 			if err := validatePromptExclusivity(repoCfg.Prompt, repoCfg.PromptFile, "repo config"); err != nil {
@@ -149,21 +300,42 @@ func Load() (Config, error) {
 				promptFilePath = repoPath
 				promptFileRepoRoot = repoRoot
 			}
+			if repoCfg.PromptTmpl != "" {
+				cfg.PromptTmpl = repoCfg.PromptTmpl
+				templatePath = repoPath
+				templateRepoRoot = repoRoot
+			}
 			if repoCfg.Engines != nil {
 				if cfg.Engines == nil {
 					cfg.Engines = map[string]EngineConfig{}
 				}
 				maps.Copy(cfg.Engines, repoCfg.Engines)
 			}
-			if repoCfg.Filter.MaxFileLines != 0 {
-				cfg.Filter.MaxFileLines = repoCfg.Filter.MaxFileLines
-			}
-			if len(repoCfg.Filter.DefaultExcludePatterns) > 0 {
-				cfg.Filter.DefaultExcludePatterns = repoCfg.Filter.DefaultExcludePatterns
-			}
-			if len(repoCfg.Filter.ExcludePatterns) > 0 {
-				cfg.Filter.ExcludePatterns = append(cfg.Filter.ExcludePatterns, repoCfg.Filter.ExcludePatterns...)
+			mergeFilterConfig(&cfg.Filter, repoCfg.Filter)
+			mergeRedactConfig(&cfg.Redact, repoCfg.Redact)
+			mergePolicyConfig(&cfg.Policy, repoCfg.Policy)
+			mergeDiffConfig(&cfg.Diff, repoCfg.Diff, md.IsDefined)
+			mergeBreakingConfig(&cfg.Breaking, repoCfg.Breaking, md.IsDefined)
+			mergeRules(&cfg.Rules, repoCfg.Rules)
+			mergeScopesConfig(&cfg.Scopes, repoCfg.Scopes)
+			mergeHistoryConfig(&cfg, repoCfg.Examples, repoCfg.History, md.IsDefined)
+			for i := range repoCfg.Context.Providers {
+				repoCfg.Context.Providers[i].FromRepo = true
 			}
+			mergeContextConfig(&cfg.Context, repoCfg.Context)
+			mergeIssuesConfig(&cfg.Issues, repoCfg.Issues, md.IsDefined)
+			mergeTrailersConfig(&cfg.Trailers, repoCfg.Trailers, md.IsDefined)
+			mergeCommitConfig(&cfg.Commit, repoCfg.Commit, md.IsDefined)
+			mergeGlossaryConfig(&cfg.Glossary, repoCfg.Glossary)
+			mergeLanguage(&cfg, repoCfg.Language, repoCfg.SubjectLang)
+		}
+	}
+
+	// 4b. Repo glossary file. It shapes the prompt and rewrites messages, so
+	// like the repo TOML config it must be trusted.
+	if repoRoot != "" {
+		if err := loadGlossaryFile(&cfg.Glossary, repoRoot, filepath.Join(repoRoot, glossaryPath)); err != nil {
+			return cfg, err
 		}
 	}
 
@@ -173,12 +345,14 @@ func Load() (Config, error) {
 		return cfg, err
 	}
 	promptFilePath, promptFileRepoRoot = gitScopePromptPaths(gitScopes.local, promptFilePath, promptFileRepoRoot, cfg)
+	templatePath, templateRepoRoot = gitScopeTemplatePaths(gitScopes.local, templatePath, templateRepoRoot, cfg)
 
 	// 6. Worktree git config (highest git config priority)
 	if err := applyGitConfigScope(&cfg, gitScopes.worktree, "worktree git config", localPromptFileBase); err != nil {
 		return cfg, err
 	}
 	promptFilePath, promptFileRepoRoot = gitScopePromptPaths(gitScopes.worktree, promptFilePath, promptFileRepoRoot, cfg)
+	templatePath, templateRepoRoot = gitScopeTemplatePaths(gitScopes.worktree, templatePath, templateRepoRoot, cfg)
 
 	// 7. Auto-detect engine if still empty
 	if strings.TrimSpace(cfg.DefaultEngine) == "" {
@@ -192,17 +366,28 @@ func Load() (Config, error) {
 		cfg.Engines = map[string]EngineConfig{}
 	}
 
-	// 9. Resolve prompt from preset or file.
+	// 9. Resolve prompt from preset or file. A preset matching the
+	// repository's history is picked later, by ApplyCLIPrompt.
 	if err := resolvePromptFromPath(&cfg, promptFilePath, promptFileRepoRoot); err != nil {
 		return cfg, err
 	}
 
+	// 10. Load the prompt template, if any.
+	if strings.TrimSpace(cfg.PromptTmpl) != "" {
+		text, err := loadPromptFile("prompt_template", cfg.PromptTmpl, templatePath, templateRepoRoot)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.ResolvedTmpl = text
+	}
+
 	return cfg, nil
 }
 
 func loadConfigLayer(data []byte, cfg *Config, source string) error {
 	var raw rawConfig
-	if err := toml.Unmarshal(data, &raw); err != nil {
+	md, err := toml.Decode(string(data), &raw)
+	if err != nil {
 		return fmt.Errorf("parse %s: %w", source, err)
 	}
 	if err := validatePromptExclusivity(raw.Prompt, raw.PromptFile, source); err != nil {
@@ -220,22 +405,266 @@ func loadConfigLayer(data []byte, cfg *Config, source string) error {
 		cfg.PromptFile = raw.PromptFile
 		cfg.Prompt = ""
 	}
+	if raw.PromptTmpl != "" {
+		cfg.PromptTmpl = raw.PromptTmpl
+	}
 	if raw.Engines != nil {
 		if cfg.Engines == nil {
 			cfg.Engines = map[string]EngineConfig{}
 		}
 		maps.Copy(cfg.Engines, raw.Engines)
 	}
-	// Merge filter config
-	if raw.Filter.MaxFileLines != 0 {
-		cfg.Filter.MaxFileLines = raw.Filter.MaxFileLines
+	mergeFilterConfig(&cfg.Filter, raw.Filter)
+	mergeRedactConfig(&cfg.Redact, raw.Redact)
+	mergePolicyConfig(&cfg.Policy, raw.Policy)
+	mergeDiffConfig(&cfg.Diff, raw.Diff, md.IsDefined)
+	mergeBreakingConfig(&cfg.Breaking, raw.Breaking, md.IsDefined)
+	mergeRules(&cfg.Rules, raw.Rules)
+	mergeScopesConfig(&cfg.Scopes, raw.Scopes)
+	mergeHistoryConfig(cfg, raw.Examples, raw.History, md.IsDefined)
+	mergeContextConfig(&cfg.Context, raw.Context)
+	mergeIssuesConfig(&cfg.Issues, raw.Issues, md.IsDefined)
+	mergeTrailersConfig(&cfg.Trailers, raw.Trailers, md.IsDefined)
+	mergeCommitConfig(&cfg.Commit, raw.Commit, md.IsDefined)
+	mergeGlossaryConfig(&cfg.Glossary, raw.Glossary)
+	mergeLanguage(cfg, raw.Language, raw.SubjectLang)
+	return nil
+}
+
+// mergeFilterConfig merges one layer's filter settings into dst. Scalar and
+// override lists replace earlier values; exclude_patterns accumulate.
+func mergeFilterConfig(dst *FilterConfig, src FilterConfig) {
+	if src.MaxFileLines != 0 {
+		dst.MaxFileLines = src.MaxFileLines
+	}
+	if len(src.DefaultExcludePatterns) > 0 {
+		dst.DefaultExcludePatterns = src.DefaultExcludePatterns
 	}
-	if len(raw.Filter.DefaultExcludePatterns) > 0 {
-		cfg.Filter.DefaultExcludePatterns = raw.Filter.DefaultExcludePatterns
+	if len(src.ExcludePatterns) > 0 {
+		dst.ExcludePatterns = append(dst.ExcludePatterns, src.ExcludePatterns...)
 	}
-	if len(raw.Filter.ExcludePatterns) > 0 {
-		cfg.Filter.ExcludePatterns = append(cfg.Filter.ExcludePatterns, raw.Filter.ExcludePatterns...)
+	if len(src.GeneratedHeaders) > 0 {
+		dst.GeneratedHeaders = src.GeneratedHeaders
 	}
+}
+
+// mergeRedactConfig merges one layer's redact settings into dst. Custom
+// patterns accumulate across layers.
+func mergeRedactConfig(dst *RedactConfig, src RedactConfig) {
+	if src.Mode != "" {
+		dst.Mode = src.Mode
+	}
+	if len(src.Patterns) > 0 {
+		dst.Patterns = append(dst.Patterns, src.Patterns...)
+	}
+}
+
+// mergePolicyConfig merges one layer's policy settings into dst. never_send
+// patterns accumulate so that no layer can lift a restriction set by another.
+func mergePolicyConfig(dst *PolicyConfig, src PolicyConfig) {
+	if len(src.NeverSend) > 0 {
+		dst.NeverSend = append(dst.NeverSend, src.NeverSend...)
+	}
+	if src.OnMatch != "" {
+		dst.OnMatch = src.OnMatch
+	}
+}
+
+// definedFunc reports whether a layer sets the option at a TOML key path,
+// such as ("diff", "renames"), so that an explicit false can turn off an
+// option an earlier layer enabled. toml.MetaData.IsDefined is one.
+type definedFunc func(key ...string) bool
+
+// mergeDiffConfig merges one layer's diff settings into dst. Boolean options
+// are taken from the layer when it sets them, even to false.
+func mergeDiffConfig(dst *DiffConfig, src DiffConfig, defined definedFunc) {
+	if defined("diff", "function_context") {
+		dst.FunctionContext = src.FunctionContext
+	}
+	if src.ContextLines != 0 {
+		dst.ContextLines = src.ContextLines
+	}
+	if src.Algorithm != "" {
+		dst.Algorithm = src.Algorithm
+	}
+	if defined("diff", "renames") {
+		dst.Renames = src.Renames
+	}
+	if defined("diff", "copies") {
+		dst.Copies = src.Copies
+	}
+	if defined("diff", "ignore_space_change") {
+		dst.IgnoreSpaceChange = src.IgnoreSpaceChange
+	}
+}
+
+// mergeBreakingConfig merges one layer's breaking change settings into dst.
+func mergeBreakingConfig(dst *BreakingConfig, src BreakingConfig, defined definedFunc) {
+	if defined("breaking", "require_footer") {
+		dst.RequireFooter = src.RequireFooter
+	}
+}
+
+// mergeRules merges one layer's rule settings into dst. Each rule is
+// enabled or disabled by the last layer that mentions it.
+func mergeRules(dst *map[string]bool, src map[string]bool) {
+	if len(src) == 0 {
+		return
+	}
+	if *dst == nil {
+		*dst = make(map[string]bool)
+	}
+	for name, enabled := range src {
+		(*dst)[name] = enabled
+	}
+}
+
+// mergeScopesConfig merges one layer's scope settings into dst. Path
+// mappings from later layers add to or replace earlier ones per pattern.
+func mergeScopesConfig(dst *ScopesConfig, src ScopesConfig) {
+	if len(src.Paths) > 0 {
+		if dst.Paths == nil {
+			dst.Paths = make(map[string]string)
+		}
+		for pattern, name := range src.Paths {
+			dst.Paths[pattern] = name
+		}
+	}
+	if src.Derive != "" {
+		dst.Derive = src.Derive
+	}
+}
+
+// mergeHistoryConfig merges one layer's history example settings into cfg.
+func mergeHistoryConfig(cfg *Config, examples int, src HistoryConfig, defined definedFunc) {
+	if examples != 0 {
+		cfg.Examples = examples
+	}
+	if defined("history", "same_paths") {
+		cfg.History.SamePaths = src.SamePaths
+	}
+	if defined("history", "include_bots") {
+		cfg.History.IncludeBots = src.IncludeBots
+	}
+	if defined("history", "include_reverts") {
+		cfg.History.IncludeReverts = src.IncludeReverts
+	}
+}
+
+// mergeContextConfig merges one layer's context settings into dst. A
+// provider replaces an earlier one with the same name.
+func mergeContextConfig(dst *ContextConfig, src ContextConfig) {
+	for _, p := range src.Providers {
+		i := slices.IndexFunc(dst.Providers, func(q ContextProvider) bool { return q.Name == p.Name })
+		if i >= 0 {
+			dst.Providers[i] = p
+		} else {
+			dst.Providers = append(dst.Providers, p)
+		}
+	}
+	if src.RelatedHistory != 0 {
+		dst.RelatedHistory = src.RelatedHistory
+	}
+	if src.RelatedHistoryMaxBytes != 0 {
+		dst.RelatedHistoryMaxBytes = src.RelatedHistoryMaxBytes
+	}
+}
+
+// mergeIssuesConfig merges one layer's issue key settings into dst. Branch
+// patterns accumulate across layers.
+func mergeIssuesConfig(dst *IssuesConfig, src IssuesConfig, defined definedFunc) {
+	dst.BranchPatterns = append(dst.BranchPatterns, src.BranchPatterns...)
+	if src.Placement != "" {
+		dst.Placement = src.Placement
+	}
+	if src.Trailer != "" {
+		dst.Trailer = src.Trailer
+	}
+	if src.PrefixFormat != "" {
+		dst.PrefixFormat = src.PrefixFormat
+	}
+	if defined("issues", "required") {
+		dst.Required = src.Required
+	}
+}
+
+// mergeTrailersConfig merges one layer's trailer settings into dst.
+func mergeTrailersConfig(dst *TrailersConfig, src TrailersConfig, defined definedFunc) {
+	dst.Static = append(dst.Static, src.Static...)
+	if defined("trailers", "signoff") {
+		dst.Signoff = src.Signoff
+	}
+}
+
+// mergeCommitConfig merges one layer's git commit defaults into dst.
+func mergeCommitConfig(dst *CommitConfig, src CommitConfig, defined definedFunc) {
+	if defined("commit", "sign") {
+		dst.Sign = src.Sign
+	}
+	if src.SignKey != "" {
+		dst.SignKey = src.SignKey
+	}
+	if defined("commit", "no_verify") {
+		dst.NoVerify = src.NoVerify
+	}
+	if src.Author != "" {
+		dst.Author = src.Author
+	}
+	if src.Cleanup != "" {
+		dst.Cleanup = src.Cleanup
+	}
+}
+
+// mergeLanguage merges one layer's message languages into cfg.
+func mergeLanguage(cfg *Config, language, subject string) {
+	if language != "" {
+		cfg.Language = language
+	}
+	if subject != "" {
+		cfg.SubjectLang = subject
+	}
+}
+
+// mergeGlossaryConfig merges one layer's glossary into dst. Terms override
+// earlier definitions of the same term; forbidden terms accumulate.
+func mergeGlossaryConfig(dst *GlossaryConfig, src GlossaryConfig) {
+	if len(src.Prefer) > 0 {
+		if dst.Prefer == nil {
+			dst.Prefer = map[string]string{}
+		}
+		maps.Copy(dst.Prefer, src.Prefer)
+	}
+	dst.Forbidden = append(dst.Forbidden, src.Forbidden...)
+	if len(src.Definitions) > 0 {
+		if dst.Definitions == nil {
+			dst.Definitions = map[string]string{}
+		}
+		maps.Copy(dst.Definitions, src.Definitions)
+	}
+}
+
+// glossaryPath is the repository's glossary file, relative to its root.
+var glossaryPath = filepath.Join(".git-ai-commit", "glossary.toml")
+
+// loadGlossaryFile merges the glossary file at path, which holds the
+// contents of a [glossary] table, into dst once the user trusts it, as for
+// the repo config. A missing file is ignored.
+func loadGlossaryFile(dst *GlossaryConfig, repoRoot, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read glossary: %w", err)
+	}
+	data, trusted, err := loadTrustedRepoConfig(repoRoot, path)
+	if err != nil || !trusted {
+		return err
+	}
+	var glossary GlossaryConfig
+	if err := toml.Unmarshal(data, &glossary); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	mergeGlossaryConfig(dst, glossary)
 	return nil
 }
 
@@ -249,7 +678,7 @@ func validatePromptExclusivity(prompt, promptFile, source string) error {
 func resolvePromptFromPath(cfg *Config, promptFilePath, promptFileRepoRoot string) error {
 	// If prompt_file is set, load from file (relative to config file's directory)
 	if strings.TrimSpace(cfg.PromptFile) != "" {
-		promptText, err := loadPromptFile(cfg.PromptFile, promptFilePath, promptFileRepoRoot)
+		promptText, err := loadPromptFile("prompt_file", cfg.PromptFile, promptFilePath, promptFileRepoRoot)
 		if err != nil {
 			return err
 		}
@@ -270,7 +699,10 @@ func resolvePromptFromPath(cfg *Config, promptFilePath, promptFileRepoRoot strin
 	return nil
 }
 
-func loadPromptFile(promptFile, promptFilePath, promptFileRepoRoot string) (string, error) {
+// loadPromptFile reads promptFile, relative to the directory of the config
+// file at promptFilePath. When promptFileRepoRoot is set, the file must be
+// within it. key names the setting in errors.
+func loadPromptFile(key, promptFile, promptFilePath, promptFileRepoRoot string) (string, error) {
 	var basePath string
 	if promptFilePath != "" {
 		basePath = filepath.Dir(promptFilePath)
@@ -284,19 +716,19 @@ func loadPromptFile(promptFile, promptFilePath, promptFileRepoRoot string) (stri
 	}
 	if promptFileRepoRoot != "" {
 		if filepath.IsAbs(promptFile) {
-			return "", fmt.Errorf("prompt_file must be within repo root")
+			return "", fmt.Errorf("%s must be within repo root", key)
 		}
-		allowed, err := isPathWithinRoot(fullPath, promptFileRepoRoot)
+		allowed, err := IsPathWithinRoot(fullPath, promptFileRepoRoot)
 		if err != nil {
 			return "", err
 		}
 		if !allowed {
-			return "", fmt.Errorf("prompt_file must be within repo root")
+			return "", fmt.Errorf("%s must be within repo root", key)
 		}
 	}
 	data, err := os.ReadFile(fullPath)
 	if err != nil {
-		return "", fmt.Errorf("read prompt file %q: %w", fullPath, err)
+		return "", fmt.Errorf("read %s %q: %w", strings.ReplaceAll(key, "_", " "), fullPath, err)
 	}
 	return strings.TrimSpace(string(data)), nil
 }
@@ -352,6 +784,69 @@ func LoadPromptPreset(name string) (string, error) {
 	return strings.TrimSpace(string(data)), nil
 }
 
+// coAuthorsName is the alias file for --co-author in the user config
+// directory.
+const coAuthorsName = "co-authors.toml"
+
+// LoadCoAuthors reads the co-author aliases from co-authors.toml in the user
+// config directory, mapping each alias to "Name <email>". A missing file
+// yields no aliases.
+func LoadCoAuthors() (map[string]string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, coAuthorsName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read co-author aliases: %w", err)
+	}
+	var aliases map[string]string
+	if err := toml.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return aliases, nil
+}
+
+// conventionSample is the number of recent commit subjects used to detect
+// the repository's commit convention.
+const conventionSample = 50
+
+// detectConvention classifies the subjects of the most recent commits. It
+// reports false when there is no history or no clear convention.
+func detectConvention() (convention.Result, bool) {
+	cmd := exec.Command("git", "log", "--no-merges", "-n", strconv.Itoa(conventionSample), "--format=%s")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return convention.Result{}, false
+	}
+	return convention.Classify(strings.Split(stdout.String(), "\n"))
+}
+
+// detectPrompt selects the preset detected by detectConvention when no layer
+// set a prompt or prompt_file. History is only read in that case.
+func detectPrompt(cfg *Config) error {
+	if strings.TrimSpace(cfg.Prompt) != "" || strings.TrimSpace(cfg.PromptFile) != "" {
+		return nil
+	}
+	detected, ok := detectConvention()
+	if !ok {
+		return nil
+	}
+	promptText, err := LoadPromptPreset(detected.Preset)
+	if err != nil {
+		return err
+	}
+	cfg.Prompt = detected.Preset
+	cfg.ResolvedPrompt = promptText
+	cfg.DetectedPrompt = detected.String()
+	return nil
+}
+
 func autodetectEngine() string {
 	candidates := []string{"claude", "gemini", "codex"}
 	for _, name := range candidates {
@@ -365,7 +860,12 @@ func autodetectEngine() string {
 // ApplyCLIPrompt applies CLI-level prompt or prompt_file overrides.
 // Both prompt and promptFile should not be set at the same time (caller must validate).
 // If promptFile is set, it is resolved relative to the current working directory.
+// Without either, and with no prompt or prompt_file configured, it picks the
+// preset matching the repository's history.
 func ApplyCLIPrompt(cfg *Config, prompt, promptFile string) error {
+	if strings.TrimSpace(prompt) == "" && strings.TrimSpace(promptFile) == "" {
+		return detectPrompt(cfg)
+	}
 	if strings.TrimSpace(prompt) != "" {
 		cfg.Prompt = prompt
 		cfg.PromptFile = ""
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -388,8 +391,8 @@ func TestUntrustedRepoConfigNonInteractive(t *testing.T) {
 
 	withDir(t, repo, func() {
 		_, err := Load()
-		if err == nil {
-			t.Fatal("expected error for untrusted repo config")
+		if !errors.Is(err, ErrTrustPrompt) {
+			t.Fatalf("Load error = %v, want ErrTrustPrompt", err)
 		}
 	})
 }
@@ -424,6 +427,63 @@ func TestRepoPromptFileOutsideRootRejected(t *testing.T) {
 	})
 }
 
+func TestPromptTemplateLoading(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "prompt.tmpl"), []byte("{{.Diff}}\n"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	data := []byte("prompt = 'conventional'\nprompt_template = 'prompt.tmpl'\n")
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	withDir(t, t.TempDir(), func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.ResolvedTmpl != "{{.Diff}}" {
+			t.Fatalf("ResolvedTmpl = %q, want %q", cfg.ResolvedTmpl, "{{.Diff}}")
+		}
+		if cfg.Prompt != "conventional" {
+			t.Fatalf("Prompt = %q, want the preset kept alongside the template", cfg.Prompt)
+		}
+	})
+}
+
+func TestRepoPromptTemplateOutsideRootRejected(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+
+	if err := os.WriteFile(filepath.Join(base, "secret.tmpl"), []byte("{{.Diff}}"), 0o644); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	repoConfig := filepath.Join(repo, ".git-ai-commit.toml")
+	if err := os.WriteFile(repoConfig, []byte("prompt_template = '../secret.tmpl'\n"), 0o644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	trustRepoConfig(t, repo, repoConfig)
+
+	withDir(t, repo, func() {
+		_, err := Load()
+		if err == nil || !strings.Contains(err.Error(), "prompt_template must be within repo root") {
+			t.Fatalf("Load error = %v, want prompt_template outside repo root rejected", err)
+		}
+	})
+}
+
 func TestRepoPromptFileAbsolutePathRejected(t *testing.T) {
 	base := t.TempDir()
 	repo := filepath.Join(base, "repo")
@@ -603,3 +663,404 @@ func trustRepoConfig(t *testing.T, repoRoot, repoConfigPath string) {
 		t.Fatalf("write trusted repo list: %v", err)
 	}
 }
+
+func TestPolicyNeverSendAccumulates(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+
+	repoConfig := filepath.Join(repo, ".git-ai-commit.toml")
+	if err := os.WriteFile(repoConfig, []byte("[policy]\nnever_send = ['fixtures/customers/**']\n"), 0o644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	userConfig := []byte("[policy]\nnever_send = ['*.pem']\non_match = 'strip'\n")
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), userConfig, 0o644); err != nil {
+		t.Fatalf("write user config: %v", err)
+	}
+
+	trustRepoConfig(t, repo, repoConfig)
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := []string{"*.pem", "fixtures/customers/**"}
+		if len(cfg.Policy.NeverSend) != 2 || cfg.Policy.NeverSend[0] != want[0] || cfg.Policy.NeverSend[1] != want[1] {
+			t.Fatalf("Policy.NeverSend = %v, want %v", cfg.Policy.NeverSend, want)
+		}
+		if cfg.Policy.OnMatch != PolicyStrip {
+			t.Fatalf("Policy.OnMatch = %q, want strip", cfg.Policy.OnMatch)
+		}
+	})
+}
+
+func TestDiffConfigMerging(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	data := []byte("[diff]\nfunction_context = true\ncontext_lines = 8\nalgorithm = 'histogram'\nrenames = true\ncopies = true\nignore_space_change = true\n")
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	withDir(t, t.TempDir(), func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := DiffConfig{FunctionContext: true, ContextLines: 8, Algorithm: "histogram", Renames: true, Copies: true, IgnoreSpaceChange: true}
+		if cfg.Diff != want {
+			t.Fatalf("Diff = %+v, want %+v", cfg.Diff, want)
+		}
+	})
+}
+
+func TestBoolsTurnedOffByLaterLayer(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	data := []byte("[diff]\nfunction_context = true\nrenames = true\ncopies = true\n\n[breaking]\nrequire_footer = true\n\n[history]\nsame_paths = true\n\n[issues]\nrequired = true\n\n[trailers]\nsignoff = true\n\n[commit]\nsign = true\nno_verify = true\n")
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	repoConfig := filepath.Join(repo, ".git-ai-commit.toml")
+	if err := os.WriteFile(repoConfig, []byte("[diff]\nrenames = false\n"), 0o644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+	trustRepoConfig(t, repo, repoConfig)
+	setGitConfig(t, repo, "ai-commit.functionContext", "false")
+	setGitConfig(t, repo, "ai-commit.requireBreakingFooter", "false")
+	setGitConfig(t, repo, "ai-commit.historySamePaths", "false")
+	setGitConfig(t, repo, "ai-commit.issueRequired", "false")
+	setGitConfig(t, repo, "ai-commit.signoff", "false")
+	setGitConfig(t, repo, "ai-commit.sign", "false")
+	setGitConfig(t, repo, "ai-commit.noVerify", "false")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if want := (DiffConfig{Copies: true}); cfg.Diff != want {
+			t.Fatalf("Diff = %+v, want %+v", cfg.Diff, want)
+		}
+		if cfg.Breaking.RequireFooter || cfg.History.SamePaths || cfg.Issues.Required || cfg.Trailers.Signoff || cfg.Commit.Sign || cfg.Commit.NoVerify {
+			t.Fatalf("Breaking = %+v, History = %+v, Issues = %+v, Trailers = %+v, Commit = %+v, want all turned off", cfg.Breaking, cfg.History, cfg.Issues, cfg.Trailers, cfg.Commit)
+		}
+	})
+}
+
+func TestRulesLaterLayerWins(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	data := []byte("[rules]\nrename = false\nversion = false\n")
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	setGitConfig(t, repo, "ai-commit.rules.rename", "true")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if !cfg.Rules["rename"] || cfg.Rules["version"] {
+			t.Fatalf("Rules = %v, want rename enabled and version disabled", cfg.Rules)
+		}
+	})
+}
+
+func TestHistoryConfig(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	data := []byte("history_examples = 3\n\n[history]\nsame_paths = true\n")
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	setGitConfig(t, repo, "ai-commit.historyExamples", "5")
+	setGitConfig(t, repo, "ai-commit.historyIncludeReverts", "true")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := HistoryConfig{SamePaths: true, IncludeReverts: true}
+		if cfg.Examples != 5 || cfg.History != want {
+			t.Fatalf("Examples = %d, History = %+v", cfg.Examples, cfg.History)
+		}
+	})
+}
+
+func TestPromptDetectedFromHistory(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	setGitConfig(t, repo, "user.name", "Test")
+	setGitConfig(t, repo, "user.email", "test@example.com")
+	for _, subject := range []string{"feat(cli): add flag", "fix(parser): handle eof", "docs(readme): fix typo", "test(parser): cover eof", "chore(deps): bump toml"} {
+		runGit(t, repo, "commit", "--allow-empty", "-m", subject)
+	}
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.Prompt != "" || cfg.DetectedPrompt != "" {
+			t.Fatalf("Prompt = %q, DetectedPrompt = %q after Load, want detection left to ApplyCLIPrompt", cfg.Prompt, cfg.DetectedPrompt)
+		}
+		cliCfg := cfg
+		if err := ApplyCLIPrompt(&cliCfg, "gitmoji", ""); err != nil {
+			t.Fatalf("ApplyCLIPrompt error: %v", err)
+		}
+		if cliCfg.Prompt != "gitmoji" || cliCfg.DetectedPrompt != "" {
+			t.Fatalf("Prompt = %q, DetectedPrompt = %q with CLI prompt, want gitmoji undetected", cliCfg.Prompt, cliCfg.DetectedPrompt)
+		}
+		if err := ApplyCLIPrompt(&cfg, "", ""); err != nil {
+			t.Fatalf("ApplyCLIPrompt error: %v", err)
+		}
+		want, err := LoadPromptPreset("karma")
+		if err != nil {
+			t.Fatalf("LoadPromptPreset error: %v", err)
+		}
+		if cfg.Prompt != "karma" || cfg.DetectedPrompt == "" || cfg.ResolvedPrompt != want {
+			t.Fatalf("Prompt = %q, DetectedPrompt = %q, want karma detected", cfg.Prompt, cfg.DetectedPrompt)
+		}
+	})
+
+	setGitConfig(t, repo, "ai-commit.prompt", "default")
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if err := ApplyCLIPrompt(&cfg, "", ""); err != nil {
+			t.Fatalf("ApplyCLIPrompt error: %v", err)
+		}
+		if cfg.Prompt != "default" || cfg.DetectedPrompt != "" {
+			t.Fatalf("Prompt = %q, DetectedPrompt = %q, want configured prompt kept", cfg.Prompt, cfg.DetectedPrompt)
+		}
+	})
+}
+
+func TestTrailersConfig(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	data := []byte("[trailers]\nstatic = [\"Team: payments\"]\n")
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	aliases := []byte("jd = \"Jane Doe <jane@example.com>\"\n")
+	if err := os.WriteFile(filepath.Join(configDir, "co-authors.toml"), aliases, 0o644); err != nil {
+		t.Fatalf("write co-authors: %v", err)
+	}
+	addGitConfig(t, repo, "ai-commit.trailer", "Reviewed-by: Bob <bob@example.com>")
+	setGitConfig(t, repo, "ai-commit.signoff", "true")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := []string{"Team: payments", "Reviewed-by: Bob <bob@example.com>"}
+		if !slices.Equal(cfg.Trailers.Static, want) || !cfg.Trailers.Signoff {
+			t.Fatalf("Trailers = %+v", cfg.Trailers)
+		}
+		got, err := LoadCoAuthors()
+		if err != nil {
+			t.Fatalf("LoadCoAuthors error: %v", err)
+		}
+		if got["jd"] != "Jane Doe <jane@example.com>" {
+			t.Fatalf("LoadCoAuthors = %v", got)
+		}
+	})
+}
+
+func TestContextProvidersMerge(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+
+	repoConfig := filepath.Join(repo, ".git-ai-commit.toml")
+	repoData := "[[context.providers]]\nname = 'tests'\ncommand = 'make test'\ntimeout = '1m'\n\n[[context.providers]]\nname = 'ticket'\nfiles = '.ticket.md'\n"
+	if err := os.WriteFile(repoConfig, []byte(repoData), 0o644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	userData := "[[context.providers]]\nname = 'tests'\ncommand = 'go test ./...'\n\n[[context.providers]]\nname = 'todo'\nfiles = 'TODO'\nmax_bytes = 100\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(userData), 0o644); err != nil {
+		t.Fatalf("write user config: %v", err)
+	}
+
+	trustRepoConfig(t, repo, repoConfig)
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := []ContextProvider{
+			{Name: "tests", Command: "make test", Timeout: "1m", FromRepo: true},
+			{Name: "todo", Files: "TODO", MaxBytes: 100},
+			{Name: "ticket", Files: ".ticket.md", FromRepo: true},
+		}
+		if !slices.Equal(cfg.Context.Providers, want) {
+			t.Fatalf("Context.Providers = %+v, want %+v", cfg.Context.Providers, want)
+		}
+	})
+}
+
+func TestRepoProviderFilesOutsideRootRejected(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+
+	repoConfig := filepath.Join(repo, ".git-ai-commit.toml")
+	if err := os.WriteFile(repoConfig, []byte("[[context.providers]]\nname = 'keys'\nfiles = '../../.ssh/*'\n"), 0o644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	trustRepoConfig(t, repo, repoConfig)
+
+	withDir(t, repo, func() {
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for provider files outside repo root")
+		}
+	})
+}
+
+func TestProviderCommands(t *testing.T) {
+	data := []byte("[[context.providers]]\nname = 'tests'\ncommand = 'go test ./...'\n\n[[context.providers]]\nname = 'ticket'\nfiles = '.ticket.md'\n")
+	if got := providerCommands(data); !slices.Equal(got, []string{"go test ./..."}) {
+		t.Fatalf("providerCommands = %q", got)
+	}
+}
+
+func TestGlossaryMerge(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git-ai-commit"), 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+	glossary := "forbidden = ['simply']\n\n[prefer]\n'config file' = 'manifest'\n\n[definitions]\nmanifest = 'the file listing the build inputs'\n"
+	glossaryFile := filepath.Join(repo, ".git-ai-commit", "glossary.toml")
+	if err := os.WriteFile(glossaryFile, []byte(glossary), 0o644); err != nil {
+		t.Fatalf("write glossary: %v", err)
+	}
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	userConfig := "[glossary]\nforbidden = ['obviously']\n\n[glossary.prefer]\n'config file' = 'settings file'\ngithub = 'GitHub'\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(userConfig), 0o644); err != nil {
+		t.Fatalf("write user config: %v", err)
+	}
+
+	trustRepoConfig(t, repo, glossaryFile)
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		g := cfg.Glossary
+		if g.Prefer["config file"] != "manifest" || g.Prefer["github"] != "GitHub" {
+			t.Fatalf("Glossary.Prefer = %v", g.Prefer)
+		}
+		if !slices.Equal(g.Forbidden, []string{"obviously", "simply"}) {
+			t.Fatalf("Glossary.Forbidden = %v", g.Forbidden)
+		}
+		if g.Definitions["manifest"] == "" {
+			t.Fatalf("Glossary.Definitions = %v", g.Definitions)
+		}
+	})
+}
+
+func TestGlossaryFileRequiresTrust(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Join(repo, ".git-ai-commit"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	glossaryFile := filepath.Join(repo, ".git-ai-commit", "glossary.toml")
+	if err := os.WriteFile(glossaryFile, []byte("[prefer]\nbug = 'feature'\n"), 0o644); err != nil {
+		t.Fatalf("write glossary: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = origStdin
+	}()
+
+	withDir(t, repo, func() {
+		if _, err := Load(); !errors.Is(err, ErrTrustPrompt) {
+			t.Fatalf("Load error = %v, want %v", err, ErrTrustPrompt)
+		}
+		trustRepoConfig(t, repo, glossaryFile)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.Glossary.Prefer["bug"] != "feature" {
+			t.Fatalf("Glossary.Prefer = %v", cfg.Glossary.Prefer)
+		}
+	})
+}
@@ -14,10 +14,35 @@ type gitConfigScope struct {
 	engine                 string
 	prompt                 string
 	promptFile             string
+	promptTemplate         string
 	maxFileLines           int
 	maxFileLinesSet        bool
 	excludePatterns        []string
 	defaultExcludePatterns []string
+	generatedHeaders       []string
+	redactMode             string
+	redactPatterns         []string
+	neverSend              []string
+	policyOnMatch          string
+	diff                   DiffConfig
+	requireBreakingFooter  bool
+	rules                  map[string]bool
+	scopes                 ScopesConfig
+	historyExamples        int
+	history                HistoryConfig
+	context                ContextConfig
+	issues                 IssuesConfig
+	trailers               TrailersConfig
+	commit                 CommitConfig
+	language               string
+	subjectLanguage        string
+
+	// defined holds the TOML key paths, e.g. "diff.renames", of the
+	// booleans the scope sets, so that false overrides an earlier layer.
+	defined map[string]bool
+
+	// invalidKey names the first key with a value that could not be parsed.
+	invalidKey string
 }
 
 // gitConfigScopes holds settings parsed from all git config scopes.
@@ -78,8 +103,14 @@ func readGitConfigScopes() (gitConfigScopes, error) {
 			lyr.engine = value
 		case "ai-commit.prompt":
 			lyr.prompt = value
+		case "ai-commit.language":
+			lyr.language = value
+		case "ai-commit.subjectlanguage":
+			lyr.subjectLanguage = value
 		case "ai-commit.promptfile":
 			lyr.promptFile = value
+		case "ai-commit.prompttemplate":
+			lyr.promptTemplate = value
 		case "ai-commit.maxfilelines":
 			n, err := strconv.Atoi(value)
 			if err != nil {
@@ -93,18 +124,149 @@ func readGitConfigScopes() (gitConfigScopes, error) {
 			lyr.excludePatterns = append(lyr.excludePatterns, value)
 		case "ai-commit.defaultexcludepatterns":
 			lyr.defaultExcludePatterns = append(lyr.defaultExcludePatterns, value)
+		case "ai-commit.generatedheaders":
+			lyr.generatedHeaders = append(lyr.generatedHeaders, value)
+		case "ai-commit.redactmode":
+			lyr.redactMode = value
+		case "ai-commit.redactpatterns":
+			lyr.redactPatterns = append(lyr.redactPatterns, value)
+		case "ai-commit.neversend":
+			lyr.neverSend = append(lyr.neverSend, value)
+		case "ai-commit.policyonmatch":
+			lyr.policyOnMatch = value
+		case "ai-commit.functioncontext":
+			lyr.diff.FunctionContext = lyr.parseBool("ai-commit.functionContext", value, "diff", "function_context")
+		case "ai-commit.contextlines":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				lyr.markInvalid("ai-commit.contextLines")
+			} else {
+				lyr.diff.ContextLines = n
+			}
+		case "ai-commit.diffalgorithm":
+			lyr.diff.Algorithm = value
+		case "ai-commit.findrenames":
+			lyr.diff.Renames = lyr.parseBool("ai-commit.findRenames", value, "diff", "renames")
+		case "ai-commit.findcopies":
+			lyr.diff.Copies = lyr.parseBool("ai-commit.findCopies", value, "diff", "copies")
+		case "ai-commit.ignorespacechange":
+			lyr.diff.IgnoreSpaceChange = lyr.parseBool("ai-commit.ignoreSpaceChange", value, "diff", "ignore_space_change")
+		case "ai-commit.requirebreakingfooter":
+			lyr.requireBreakingFooter = lyr.parseBool("ai-commit.requireBreakingFooter", value, "breaking", "require_footer")
+		case "ai-commit.scopepaths":
+			pattern, name, ok := strings.Cut(value, "=")
+			if !ok || pattern == "" || name == "" {
+				lyr.markInvalid("ai-commit.scopePaths")
+				continue
+			}
+			if lyr.scopes.Paths == nil {
+				lyr.scopes.Paths = make(map[string]string)
+			}
+			lyr.scopes.Paths[pattern] = name
+		case "ai-commit.scopederive":
+			lyr.scopes.Derive = value
+		case "ai-commit.historyexamples":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				lyr.markInvalid("ai-commit.historyExamples")
+			} else {
+				lyr.historyExamples = n
+			}
+		case "ai-commit.historysamepaths":
+			lyr.history.SamePaths = lyr.parseBool("ai-commit.historySamePaths", value, "history", "same_paths")
+		case "ai-commit.historyincludebots":
+			lyr.history.IncludeBots = lyr.parseBool("ai-commit.historyIncludeBots", value, "history", "include_bots")
+		case "ai-commit.historyincludereverts":
+			lyr.history.IncludeReverts = lyr.parseBool("ai-commit.historyIncludeReverts", value, "history", "include_reverts")
+		case "ai-commit.relatedhistory":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				lyr.markInvalid("ai-commit.relatedHistory")
+			} else {
+				lyr.context.RelatedHistory = n
+			}
+		case "ai-commit.relatedhistorymaxbytes":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				lyr.markInvalid("ai-commit.relatedHistoryMaxBytes")
+			} else {
+				lyr.context.RelatedHistoryMaxBytes = n
+			}
+		case "ai-commit.issuebranchpattern":
+			lyr.issues.BranchPatterns = append(lyr.issues.BranchPatterns, value)
+		case "ai-commit.issueplacement":
+			lyr.issues.Placement = value
+		case "ai-commit.issuetrailer":
+			lyr.issues.Trailer = value
+		case "ai-commit.issueprefixformat":
+			lyr.issues.PrefixFormat = value
+		case "ai-commit.issuerequired":
+			lyr.issues.Required = lyr.parseBool("ai-commit.issueRequired", value, "issues", "required")
+		case "ai-commit.trailer":
+			lyr.trailers.Static = append(lyr.trailers.Static, value)
+		case "ai-commit.signoff":
+			lyr.trailers.Signoff = lyr.parseBool("ai-commit.signoff", value, "trailers", "signoff")
+		case "ai-commit.sign":
+			lyr.commit.Sign = lyr.parseBool("ai-commit.sign", value, "commit", "sign")
+		case "ai-commit.signkey":
+			lyr.commit.SignKey = value
+		case "ai-commit.noverify":
+			lyr.commit.NoVerify = lyr.parseBool("ai-commit.noVerify", value, "commit", "no_verify")
+		case "ai-commit.author":
+			lyr.commit.Author = value
+		case "ai-commit.cleanup":
+			lyr.commit.Cleanup = value
+		default:
+			if name, ok := strings.CutPrefix(key, "ai-commit.rules."); ok && name != "" {
+				if lyr.rules == nil {
+					lyr.rules = make(map[string]bool)
+				}
+				lyr.rules[name] = lyr.parseBool(key, value)
+			}
 		}
 	}
 
 	return scopes, nil
 }
 
+// parseBool parses a git config boolean. Invalid values mark the key as
+// invalid and yield false. path, if given, is the TOML key path the value
+// is recorded as defined under.
+func (s *gitConfigScope) parseBool(key, value string, path ...string) bool {
+	if len(path) > 0 {
+		if s.defined == nil {
+			s.defined = make(map[string]bool)
+		}
+		s.defined[strings.Join(path, ".")] = true
+	}
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true
+	case "false", "no", "off", "0", "":
+		return false
+	}
+	s.markInvalid(key)
+	return false
+}
+
+// isDefined reports whether the scope sets the boolean at a TOML key path.
+func (s *gitConfigScope) isDefined(key ...string) bool {
+	return s.defined[strings.Join(key, ".")]
+}
+
+func (s *gitConfigScope) markInvalid(key string) {
+	if s.invalidKey == "" {
+		s.invalidKey = key
+	}
+}
+
 // applyGitConfigScope merges one git config scope into cfg.
 //
 // scopeLabel is the human-readable name used in error messages, e.g. "repo
 // git config".
 //
-// promptFileBase is the directory used to resolve relative promptFile values.
+// promptFileBase is the directory used to resolve relative promptFile and
+// promptTemplate values.
 // Pass "" to disallow relative paths (require absolute).
 func applyGitConfigScope(cfg *Config, scope gitConfigScope, scopeLabel, promptFileBase string) error {
 	// Validate maxFileLines
@@ -112,6 +274,10 @@ func applyGitConfigScope(cfg *Config, scope gitConfigScope, scopeLabel, promptFi
 		return fmt.Errorf("invalid ai-commit.maxFileLines value in %s: not an integer", scopeLabel)
 	}
 
+	if scope.invalidKey != "" {
+		return fmt.Errorf("invalid %s value in %s", scope.invalidKey, scopeLabel)
+	}
+
 	// Validate prompt exclusivity at this scope level.
 	if strings.TrimSpace(scope.prompt) != "" && strings.TrimSpace(scope.promptFile) != "" {
 		return fmt.Errorf("%s: cannot set both 'prompt' and 'promptFile'", scopeLabel)
@@ -128,6 +294,9 @@ func applyGitConfigScope(cfg *Config, scope gitConfigScope, scopeLabel, promptFi
 		cfg.PromptFile = resolvePromptFilePath(scope.promptFile, promptFileBase)
 		cfg.Prompt = ""
 	}
+	if scope.promptTemplate != "" {
+		cfg.PromptTmpl = resolvePromptFilePath(scope.promptTemplate, promptFileBase)
+	}
 	if scope.maxFileLinesSet && scope.maxFileLines >= 0 {
 		cfg.Filter.MaxFileLines = scope.maxFileLines
 	}
@@ -137,6 +306,21 @@ func applyGitConfigScope(cfg *Config, scope gitConfigScope, scopeLabel, promptFi
 	if len(scope.excludePatterns) > 0 {
 		cfg.Filter.ExcludePatterns = append(cfg.Filter.ExcludePatterns, scope.excludePatterns...)
 	}
+	if len(scope.generatedHeaders) > 0 {
+		cfg.Filter.GeneratedHeaders = scope.generatedHeaders
+	}
+	mergeRedactConfig(&cfg.Redact, RedactConfig{Mode: scope.redactMode, Patterns: scope.redactPatterns})
+	mergePolicyConfig(&cfg.Policy, PolicyConfig{NeverSend: scope.neverSend, OnMatch: scope.policyOnMatch})
+	mergeDiffConfig(&cfg.Diff, scope.diff, scope.isDefined)
+	mergeBreakingConfig(&cfg.Breaking, BreakingConfig{RequireFooter: scope.requireBreakingFooter}, scope.isDefined)
+	mergeRules(&cfg.Rules, scope.rules)
+	mergeScopesConfig(&cfg.Scopes, scope.scopes)
+	mergeHistoryConfig(cfg, scope.historyExamples, scope.history, scope.isDefined)
+	mergeContextConfig(&cfg.Context, scope.context)
+	mergeIssuesConfig(&cfg.Issues, scope.issues, scope.isDefined)
+	mergeTrailersConfig(&cfg.Trailers, scope.trailers, scope.isDefined)
+	mergeCommitConfig(&cfg.Commit, scope.commit, scope.isDefined)
+	mergeLanguage(cfg, scope.language, scope.subjectLanguage)
 
 	return nil
 }
@@ -165,3 +349,12 @@ func gitScopePromptPaths(scope gitConfigScope, curPath, curRoot string, cfg Conf
 	}
 	return curPath, curRoot
 }
+
+// gitScopeTemplatePaths is gitScopePromptPaths for promptTemplate, which
+// has no preset to clear it.
+func gitScopeTemplatePaths(scope gitConfigScope, curPath, curRoot string, cfg Config) (string, string) {
+	if scope.promptTemplate != "" {
+		return cfg.PromptTmpl, ""
+	}
+	return curPath, curRoot
+}
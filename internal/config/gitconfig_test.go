@@ -117,6 +117,27 @@ func TestGitConfigPromptFile(t *testing.T) {
 	})
 }
 
+func TestGitConfigPromptTemplate(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := os.WriteFile(filepath.Join(repo, "prompt.tmpl"), []byte("{{.Branch}}: {{.Diff}}"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	setGitConfig(t, repo, "ai-commit.promptTemplate", "prompt.tmpl")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.ResolvedTmpl != "{{.Branch}}: {{.Diff}}" {
+			t.Fatalf("ResolvedTmpl = %q", cfg.ResolvedTmpl)
+		}
+	})
+}
+
 // TestGitConfigPromptFileAbsolutePath verifies that an absolute promptFile
 // path in local git config is accepted (no containment restriction).
 func TestGitConfigPromptFileAbsolutePath(t *testing.T) {
@@ -230,6 +251,138 @@ func TestGitConfigDefaultExcludePatterns(t *testing.T) {
 	})
 }
 
+// TestGitConfigGeneratedHeaders verifies that multi-value generatedHeaders in
+// local git config overrides the built-in header patterns.
+func TestGitConfigGeneratedHeaders(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	addGitConfig(t, repo, "ai-commit.generatedHeaders", "^// Autogenerated")
+	addGitConfig(t, repo, "ai-commit.generatedHeaders", "^# DO NOT EDIT")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := []string{"^// Autogenerated", "^# DO NOT EDIT"}
+		if !slices.Equal(cfg.Filter.GeneratedHeaders, want) {
+			t.Fatalf("GeneratedHeaders = %v, want %v", cfg.Filter.GeneratedHeaders, want)
+		}
+	})
+}
+
+// TestGitConfigRedact verifies that redactMode and multi-value redactPatterns
+// in local git config are applied.
+func TestGitConfigRedact(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	setGitConfig(t, repo, "ai-commit.redactMode", "block")
+	addGitConfig(t, repo, "ai-commit.redactPatterns", "CUST-[0-9]+")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.Redact.Mode != "block" {
+			t.Fatalf("Redact.Mode = %q, want 'block'", cfg.Redact.Mode)
+		}
+		if !slices.Equal(cfg.Redact.Patterns, []string{"CUST-[0-9]+"}) {
+			t.Fatalf("Redact.Patterns = %v", cfg.Redact.Patterns)
+		}
+	})
+}
+
+// TestGitConfigDiffOptions verifies that diff generation options in local git
+// config are applied.
+func TestGitConfigDiffOptions(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	setGitConfig(t, repo, "ai-commit.functionContext", "true")
+	setGitConfig(t, repo, "ai-commit.contextLines", "5")
+	setGitConfig(t, repo, "ai-commit.diffAlgorithm", "histogram")
+	setGitConfig(t, repo, "ai-commit.findRenames", "yes")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := DiffConfig{FunctionContext: true, ContextLines: 5, Algorithm: "histogram", Renames: true}
+		if cfg.Diff != want {
+			t.Fatalf("Diff = %+v, want %+v", cfg.Diff, want)
+		}
+	})
+}
+
+func TestGitConfigBreakingAndRules(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	setGitConfig(t, repo, "ai-commit.requireBreakingFooter", "true")
+	setGitConfig(t, repo, "ai-commit.rules.deps", "false")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if !cfg.Breaking.RequireFooter {
+			t.Fatal("expected Breaking.RequireFooter to be set")
+		}
+		if enabled, ok := cfg.Rules["deps"]; !ok || enabled {
+			t.Fatalf("Rules = %v, want deps disabled", cfg.Rules)
+		}
+	})
+}
+
+func TestGitConfigScopes(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	addGitConfig(t, repo, "ai-commit.scopePaths", "cmd/**=cli")
+	addGitConfig(t, repo, "ai-commit.scopePaths", "internal/config/**=config")
+	setGitConfig(t, repo, "ai-commit.scopeDerive", "package")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.Scopes.Paths["cmd/**"] != "cli" || cfg.Scopes.Paths["internal/config/**"] != "config" || cfg.Scopes.Derive != "package" {
+			t.Fatalf("Scopes = %+v", cfg.Scopes)
+		}
+	})
+}
+
+// TestGitConfigDiffOptionsInvalid verifies that an unparsable boolean is
+// reported with its key.
+func TestGitConfigDiffOptionsInvalid(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	setGitConfig(t, repo, "ai-commit.functionContext", "maybe")
+
+	withDir(t, repo, func() {
+		_, err := Load()
+		if err == nil {
+			t.Fatal("expected error for invalid boolean")
+		}
+		if !contains(err.Error(), "ai-commit.functionContext") {
+			t.Fatalf("error should name the key: %v", err)
+		}
+	})
+}
+
 // TestGitConfigPromptExclusivity verifies that setting both prompt and
 // promptFile at the same scope returns an error.
 func TestGitConfigPromptExclusivity(t *testing.T) {
@@ -480,3 +633,103 @@ func TestGitConfigPromptFileNoRepoToml(t *testing.T) {
 		}
 	})
 }
+
+func TestGitConfigRelatedHistory(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	setGitConfig(t, repo, "ai-commit.relatedHistory", "3")
+	setGitConfig(t, repo, "ai-commit.relatedHistoryMaxBytes", "1000")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.Context.RelatedHistory != 3 || cfg.Context.RelatedHistoryMaxBytes != 1000 {
+			t.Fatalf("Context = %+v, want related history 3 within 1000 bytes", cfg.Context)
+		}
+	})
+
+	setGitConfig(t, repo, "ai-commit.relatedHistory", "many")
+	withDir(t, repo, func() {
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for invalid ai-commit.relatedHistory")
+		}
+	})
+}
+
+func TestGitConfigIssues(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	addGitConfig(t, repo, "ai-commit.issueBranchPattern", `[A-Z]+-\d+`)
+	addGitConfig(t, repo, "ai-commit.issueBranchPattern", `^issue-(\d+)`)
+	setGitConfig(t, repo, "ai-commit.issuePlacement", "prefix")
+	setGitConfig(t, repo, "ai-commit.issuePrefixFormat", "{{key}}:")
+	setGitConfig(t, repo, "ai-commit.issueRequired", "true")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if !slices.Equal(cfg.Issues.BranchPatterns, []string{`[A-Z]+-\d+`, `^issue-(\d+)`}) {
+			t.Fatalf("BranchPatterns = %v", cfg.Issues.BranchPatterns)
+		}
+		if cfg.Issues.Placement != "prefix" || cfg.Issues.PrefixFormat != "{{key}}:" || !cfg.Issues.Required {
+			t.Fatalf("Issues = %+v", cfg.Issues)
+		}
+	})
+}
+
+func TestGitConfigCommit(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	setGitConfig(t, repo, "ai-commit.sign", "true")
+	setGitConfig(t, repo, "ai-commit.signKey", "ABCD1234")
+	setGitConfig(t, repo, "ai-commit.noVerify", "yes")
+	setGitConfig(t, repo, "ai-commit.author", "Jane Doe <jane@example.com>")
+	setGitConfig(t, repo, "ai-commit.cleanup", "scissors")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		want := CommitConfig{Sign: true, SignKey: "ABCD1234", NoVerify: true, Author: "Jane Doe <jane@example.com>", Cleanup: "scissors"}
+		if cfg.Commit != want {
+			t.Fatalf("Commit = %+v, want %+v", cfg.Commit, want)
+		}
+	})
+}
+
+func TestGitConfigLanguage(t *testing.T) {
+	repo := initTestRepo(t)
+	isolateGitConfig(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "git-ai-commit")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte("language = 'en'\nsubject_language = 'en'\n"), 0o644); err != nil {
+		t.Fatalf("write user config: %v", err)
+	}
+
+	setGitConfig(t, repo, "ai-commit.language", "ja")
+
+	withDir(t, repo, func() {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if cfg.Language != "ja" || cfg.SubjectLang != "en" {
+			t.Fatalf("Language = %q, SubjectLang = %q, want ja and en", cfg.Language, cfg.SubjectLang)
+		}
+	})
+}
@@ -26,7 +26,9 @@ func realPath(path string) (string, error) {
 	return filepath.EvalSymlinks(abs)
 }
 
-func isPathWithinRoot(path, root string) (bool, error) {
+// IsPathWithinRoot reports whether path is within root once symlinks in
+// both are resolved.
+func IsPathWithinRoot(path, root string) (bool, error) {
 	pathReal, err := realPath(path)
 	if err != nil {
 		return false, err
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,6 +15,10 @@ import (
 	"github.com/BurntSushi/toml"
 )
 
+// ErrTrustPrompt reports that a repo config needs to be trusted, but stdin
+// is not a terminal to ask on.
+var ErrTrustPrompt = errors.New("cannot ask for trust without a terminal on stdin")
+
 type trustedRepoList struct {
 	Entries []trustedRepoEntry `json:"entries"`
 }
@@ -42,6 +47,9 @@ func loadTrustedRepoConfig(repoRoot, repoConfigPath string) ([]byte, bool, error
 	if err := validateRepoPromptFilePath(data, repoConfigPath, repoRoot); err != nil {
 		return nil, false, err
 	}
+	if err := validateRepoProviderFiles(data, repoConfigPath, repoRoot); err != nil {
+		return nil, false, err
+	}
 
 	trustPath, err := trustedRepoListPath()
 	if err != nil {
@@ -58,9 +66,9 @@ func loadTrustedRepoConfig(repoRoot, repoConfigPath string) ([]byte, bool, error
 	}
 	if !isInteractiveStdin() {
 		if changed {
-			return nil, false, fmt.Errorf("repo config changed: %s", repoConfigPath)
+			return nil, false, fmt.Errorf("repo config changed: %s: %w", repoConfigPath, ErrTrustPrompt)
 		}
-		return nil, false, fmt.Errorf("untrusted repo config: %s", repoConfigPath)
+		return nil, false, fmt.Errorf("untrusted repo config: %s: %w", repoConfigPath, ErrTrustPrompt)
 	}
 
 	if !promptTrust(repoConfigPath, data, changed) {
@@ -160,6 +168,12 @@ func promptTrust(path string, data []byte, changed bool) bool {
 		fmt.Fprintln(os.Stderr)
 	}
 	fmt.Fprintln(os.Stderr, "----")
+	if commands := providerCommands(data); len(commands) > 0 {
+		fmt.Fprintln(os.Stderr, "Trusting it runs these commands on every commit:")
+		for _, command := range commands {
+			fmt.Fprintf(os.Stderr, "  %s\n", command)
+		}
+	}
 	fmt.Fprint(os.Stderr, "Trust this config? [y/N]: ")
 
 	reader := bufio.NewReader(os.Stdin)
@@ -173,20 +187,25 @@ func validateRepoPromptFilePath(data []byte, repoConfigPath, repoRoot string) er
 	if err := toml.Unmarshal(data, &raw); err != nil {
 		return fmt.Errorf("parse repo config: %w", err)
 	}
-	if strings.TrimSpace(raw.PromptFile) == "" {
-		return nil
-	}
-	if filepath.IsAbs(raw.PromptFile) {
-		return fmt.Errorf("repo config %s: prompt_file must be within repo root", repoConfigPath)
-	}
-	basePath := filepath.Dir(repoConfigPath)
-	fullPath := filepath.Join(basePath, raw.PromptFile)
-	allowed, err := isPathWithinRootClean(fullPath, repoRoot)
-	if err != nil {
-		return err
-	}
-	if !allowed {
-		return fmt.Errorf("repo config %s: prompt_file must be within repo root", repoConfigPath)
+	for _, file := range []struct{ key, path string }{
+		{"prompt_file", raw.PromptFile},
+		{"prompt_template", raw.PromptTmpl},
+	} {
+		if strings.TrimSpace(file.path) == "" {
+			continue
+		}
+		if filepath.IsAbs(file.path) {
+			return fmt.Errorf("repo config %s: %s must be within repo root", repoConfigPath, file.key)
+		}
+		basePath := filepath.Dir(repoConfigPath)
+		fullPath := filepath.Join(basePath, file.path)
+		allowed, err := isPathWithinRootClean(fullPath, repoRoot)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("repo config %s: %s must be within repo root", repoConfigPath, file.key)
+		}
 	}
 	return nil
 }
@@ -208,3 +227,43 @@ func upsertTrustedEntry(list trustedRepoList, entry trustedRepoEntry, index int,
 	list.Entries = append(list.Entries, entry)
 	return list
 }
+
+// providerCommands returns the context provider commands of a repo config.
+func providerCommands(data []byte) []string {
+	var raw rawConfig
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	var commands []string
+	for _, p := range raw.Context.Providers {
+		if strings.TrimSpace(p.Command) != "" {
+			commands = append(commands, p.Command)
+		}
+	}
+	return commands
+}
+
+// validateRepoProviderFiles rejects context provider file globs in a repo
+// config that reach outside the repo root.
+func validateRepoProviderFiles(data []byte, repoConfigPath, repoRoot string) error {
+	var raw rawConfig
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parse repo config: %w", err)
+	}
+	for _, p := range raw.Context.Providers {
+		if strings.TrimSpace(p.Files) == "" {
+			continue
+		}
+		if filepath.IsAbs(p.Files) {
+			return fmt.Errorf("repo config %s: context provider %q files must be within repo root", repoConfigPath, p.Name)
+		}
+		allowed, err := isPathWithinRootClean(filepath.Join(repoRoot, p.Files), repoRoot)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("repo config %s: context provider %q files must be within repo root", repoConfigPath, p.Name)
+		}
+	}
+	return nil
+}
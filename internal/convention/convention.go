@@ -0,0 +1,130 @@
+package convention
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Presets a history can be classified as. Each names a bundled prompt preset.
+const (
+	Conventional = "conventional" // type(scope): subject, with the scope optional
+	Karma        = "karma"        // type(scope): subject, with the scope always given
+	Gitmoji      = "gitmoji"      // subject led by an emoji or :shortcode:
+	Imperative   = "default"      // plain imperative subject
+)
+
+// MinSubjects is the fewest classifiable subjects Classify needs before it
+// reports a convention.
+const MinSubjects = 5
+
+// MinConfidence is the share of subjects that must follow a convention for
+// Classify to report it.
+const MinConfidence = 0.6
+
+// karmaScopeShare is the share of conventional subjects that must carry a
+// scope for the history to count as Karma rather than Conventional Commits.
+const karmaScopeShare = 0.9
+
+// types lists the commit types recognised in conventional subjects. Other
+// words before a colon, as in "README: fix typo", are not counted.
+var types = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "tests", "build", "ci", "chore", "revert", "deps", "release"}
+
+var conventionalPattern = regexp.MustCompile(`^([a-z]+)(\([^()]+\))?!?: \S`)
+
+var shortcodePattern = regexp.MustCompile(`^:[a-z0-9_+-]+:`)
+
+// Result is the convention detected in a history.
+type Result struct {
+	Preset     string  // bundled prompt preset matching the convention
+	Confidence float64 // share of classified subjects following it
+	Matched    int     // subjects following it
+	Total      int     // subjects classified
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s (%d of %d recent commits, confidence %.2f)", r.Preset, r.Matched, r.Total, r.Confidence)
+}
+
+// Classify returns the convention followed by most of the commit subjects.
+// Reverts and fixup or squash commits are ignored. It reports false when
+// fewer than MinSubjects remain or no convention reaches MinConfidence.
+func Classify(subjects []string) (Result, bool) {
+	var total, conventional, scoped, gitmoji, imperative int
+	for _, s := range subjects {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.HasPrefix(s, "Revert \"") || strings.HasPrefix(s, "fixup! ") || strings.HasPrefix(s, "squash! ") || strings.HasPrefix(s, "amend! ") {
+			continue
+		}
+		total++
+		switch {
+		case isConventional(s):
+			conventional++
+			if conventionalPattern.FindStringSubmatch(s)[2] != "" {
+				scoped++
+			}
+		case isGitmoji(s):
+			gitmoji++
+		case isImperative(s):
+			imperative++
+		}
+	}
+	if total < MinSubjects {
+		return Result{}, false
+	}
+
+	best := Result{Preset: Imperative, Matched: imperative}
+	if gitmoji > best.Matched {
+		best = Result{Preset: Gitmoji, Matched: gitmoji}
+	}
+	if conventional > best.Matched {
+		best = Result{Preset: Conventional, Matched: conventional}
+		if float64(scoped) >= karmaScopeShare*float64(conventional) {
+			best.Preset = Karma
+		}
+	}
+	best.Total = total
+	best.Confidence = float64(best.Matched) / float64(total)
+	if best.Confidence < MinConfidence {
+		return Result{}, false
+	}
+	return best, true
+}
+
+func isConventional(s string) bool {
+	m := conventionalPattern.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	for _, t := range types {
+		if m[1] == t {
+			return true
+		}
+	}
+	return false
+}
+
+func isGitmoji(s string) bool {
+	if shortcodePattern.MatchString(s) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.Is(unicode.So, r)
+}
+
+// isImperative reports whether s reads as a plain subject in the imperative
+// mood: it starts with a word that is not in the past or progressive tense.
+func isImperative(s string) bool {
+	word, _, _ := strings.Cut(s, " ")
+	if word == "" || strings.HasSuffix(word, ":") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(word)
+	if !unicode.IsLetter(r) {
+		return false
+	}
+	word = strings.ToLower(word)
+	return !strings.HasSuffix(word, "ed") && !strings.HasSuffix(word, "ing")
+}
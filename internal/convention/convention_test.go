@@ -0,0 +1,68 @@
+package convention
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		subjects []string
+		want     string
+	}{
+		{
+			name: "conventional",
+			subjects: []string{
+				"feat: add login", "fix(auth): handle expiry", "docs: update readme",
+				"chore: bump deps", "refactor!: drop old api", "Update ci config",
+			},
+			want: Conventional,
+		},
+		{
+			name: "karma",
+			subjects: []string{
+				"feat(cli): add flag", "fix(parser): handle eof", "docs(readme): typo",
+				"test(parser): cover eof", "chore(deps): bump toml",
+			},
+			want: Karma,
+		},
+		{
+			name: "gitmoji",
+			subjects: []string{
+				"✨ add login", "🐛 fix crash", ":memo: update docs", "🔥 remove dead code", "♻️ tidy parser",
+			},
+			want: Gitmoji,
+		},
+		{
+			name: "imperative",
+			subjects: []string{
+				"Add login", "Fix crash on empty input", "Update docs", "Remove dead code",
+				"Tidy parser", "Revert \"Add login\"", "fixup! Fix crash on empty input",
+			},
+			want: Imperative,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Classify(tt.subjects)
+			if !ok {
+				t.Fatalf("Classify reported no convention")
+			}
+			if got.Preset != tt.want {
+				t.Fatalf("Preset = %q, want %q (%s)", got.Preset, tt.want, got)
+			}
+			if got.Total != 5 && got.Total != 6 {
+				t.Fatalf("Total = %d, want reverts and fixups skipped", got.Total)
+			}
+		})
+	}
+}
+
+func TestClassifyUncertain(t *testing.T) {
+	few := []string{"feat: a", "feat: b", "fix: c"}
+	if got, ok := Classify(few); ok {
+		t.Fatalf("Classify(%d subjects) = %s, want no convention", len(few), got)
+	}
+	mixed := []string{"feat: a", "✨ b", "Added c", "fixing d", "WIP", "fix: e", ":bug: f"}
+	if got, ok := Classify(mixed); ok {
+		t.Fatalf("Classify(mixed) = %s, want no convention", got)
+	}
+}
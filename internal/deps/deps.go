@@ -0,0 +1,459 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"git-ai-commit/internal/message"
+)
+
+// Change actions reported in Change.Action.
+const (
+	Added      = "added"
+	Removed    = "removed"
+	Upgraded   = "upgraded"
+	Downgraded = "downgraded"
+	Changed    = "changed" // versions that cannot be ordered, e.g. ranges
+)
+
+// Change describes one dependency change in a manifest.
+type Change struct {
+	Manifest string
+	Name     string
+	Action   string
+	From     string // version before the change; empty when added
+	To       string // version after the change; empty when removed
+}
+
+// Describe returns an imperative description of the change, e.g.
+// "bump golang.org/x/text from v0.14.0 to v0.15.0".
+func (c Change) Describe() string {
+	switch c.Action {
+	case Added:
+		if c.To == "" {
+			return "add " + c.Name
+		}
+		return fmt.Sprintf("add %s %s", c.Name, c.To)
+	case Removed:
+		return "remove " + c.Name
+	case Upgraded:
+		return fmt.Sprintf("bump %s from %s to %s", c.Name, c.From, c.To)
+	case Downgraded:
+		return fmt.Sprintf("downgrade %s from %s to %s", c.Name, c.From, c.To)
+	}
+	return fmt.Sprintf("change %s from %s to %s", c.Name, c.From, c.To)
+}
+
+// Message returns a commit message describing changes, styled for the
+// named prompt preset with the given scope, usually "deps".
+func Message(preset, scope string, changes []Change) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	kind := message.Kind{Type: "chore", Scope: scope, Emoji: emoji(changes)}
+	if len(changes) == 1 {
+		return message.Subject(preset, kind, changes[0].Describe())
+	}
+
+	verb := "bump"
+	manifests := make(map[string]bool)
+	for _, c := range changes {
+		if c.Action != Upgraded {
+			verb = "update"
+		}
+		manifests[c.Manifest] = true
+	}
+	var b strings.Builder
+	b.WriteString(message.Subject(preset, kind, fmt.Sprintf("%s %d dependencies", verb, len(changes))))
+	b.WriteString("\n\n")
+	for _, c := range changes {
+		b.WriteString("- " + c.Describe())
+		if len(manifests) > 1 {
+			b.WriteString(" (" + c.Manifest + ")")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// emoji returns the gitmoji for a set of dependency changes.
+func emoji(changes []Change) string {
+	action := changes[0].Action
+	for _, c := range changes[1:] {
+		if c.Action != action {
+			return "⬆️"
+		}
+	}
+	switch action {
+	case Added:
+		return "➕"
+	case Removed:
+		return "➖"
+	case Downgraded:
+		return "⬇️"
+	}
+	return "⬆️"
+}
+
+// manifest parses a dependency manifest into its dependencies, keyed by
+// name, and the remaining content, used to tell whether anything other than
+// dependencies changed.
+type manifest func(src []byte) (map[string]string, any, error)
+
+// manifestFor returns the parser for a manifest path, or nil.
+func manifestFor(file string) manifest {
+	base := path.Base(file)
+	switch {
+	case base == "go.mod":
+		return parseGoMod
+	case base == "package.json":
+		return parsePackageJSON
+	case base == "Cargo.toml":
+		return parseCargoToml
+	case base == "pyproject.toml":
+		return parsePyproject
+	case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+		return parseRequirements
+	}
+	return nil
+}
+
+// IsManifest reports whether file is a dependency manifest understood by
+// Compare.
+func IsManifest(file string) bool {
+	return manifestFor(file) != nil
+}
+
+var lockFiles = map[string]bool{
+	"go.sum":            true,
+	"go.work.sum":       true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"bun.lockb":         true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+	"uv.lock":           true,
+	"Pipfile.lock":      true,
+}
+
+// IsLockFile reports whether file is a lock or checksum file that changes
+// along with a manifest.
+func IsLockFile(file string) bool {
+	return lockFiles[path.Base(file)]
+}
+
+// Compare returns the dependency changes between two versions of a
+// manifest, sorted by name. oldSrc is nil for added manifests and newSrc is
+// nil for deleted ones. onlyDeps reports whether the manifest existed before
+// and after and nothing but its dependencies changed.
+func Compare(file string, oldSrc, newSrc []byte) (changes []Change, onlyDeps bool, err error) {
+	parse := manifestFor(file)
+	if parse == nil {
+		return nil, false, fmt.Errorf("%s: not a dependency manifest", file)
+	}
+	oldDeps, oldRest := map[string]string{}, any(nil)
+	if oldSrc != nil {
+		if oldDeps, oldRest, err = parse(oldSrc); err != nil {
+			return nil, false, fmt.Errorf("parse %s: %w", file, err)
+		}
+	}
+	newDeps, newRest := map[string]string{}, any(nil)
+	if newSrc != nil {
+		if newDeps, newRest, err = parse(newSrc); err != nil {
+			return nil, false, fmt.Errorf("parse %s: %w", file, err)
+		}
+	}
+
+	names := make(map[string]bool)
+	for name := range oldDeps {
+		names[name] = true
+	}
+	for name := range newDeps {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		from, inOld := oldDeps[name]
+		to, inNew := newDeps[name]
+		c := Change{Manifest: file, Name: name, From: from, To: to}
+		switch {
+		case !inOld:
+			c.Action = Added
+		case !inNew:
+			c.Action = Removed
+		case from == to:
+			continue
+		default:
+			c.Action = Changed
+			if cmp, ok := compareVersions(from, to); ok {
+				if cmp < 0 {
+					c.Action = Upgraded
+				} else if cmp > 0 {
+					c.Action = Downgraded
+				}
+			}
+		}
+		changes = append(changes, c)
+	}
+	onlyDeps = oldSrc != nil && newSrc != nil && reflect.DeepEqual(oldRest, newRest)
+	return changes, onlyDeps, nil
+}
+
+var versionNumber = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// compareVersions compares the first dotted version number in a and b. It
+// returns false when either has none or the numbers are equal but the
+// versions differ otherwise, as for pre-releases or changed ranges.
+func compareVersions(a, b string) (int, bool) {
+	va, vb := versionNumber.FindString(a), versionNumber.FindString(b)
+	if va == "" || vb == "" {
+		return 0, false
+	}
+	pa, pb := strings.Split(va, "."), strings.Split(vb, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+// parseGoMod reads require directives from a go.mod file.
+func parseGoMod(src []byte) (map[string]string, any, error) {
+	deps := make(map[string]string)
+	var rest []string
+	inRequire := false
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(stripGoModComment(line))
+		switch {
+		case inRequire && line == ")":
+			inRequire = false
+		case inRequire:
+			if len(fields) >= 2 {
+				deps[fields[0]] = fields[1]
+			}
+		case len(fields) == 2 && fields[0] == "require" && fields[1] == "(":
+			inRequire = true
+		case len(fields) >= 3 && fields[0] == "require":
+			deps[fields[1]] = fields[2]
+		case line != "":
+			rest = append(rest, line)
+		}
+	}
+	return deps, rest, nil
+}
+
+func stripGoModComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+var packageJSONSections = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+
+// parsePackageJSON reads the dependency sections of an npm package.json.
+func parsePackageJSON(src []byte) (map[string]string, any, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(src, &doc); err != nil {
+		return nil, nil, err
+	}
+	deps := make(map[string]string)
+	for _, section := range packageJSONSections {
+		if m, ok := doc[section].(map[string]any); ok {
+			for name, v := range m {
+				if version, ok := v.(string); ok {
+					deps[name] = version
+				}
+			}
+		}
+		delete(doc, section)
+	}
+	return deps, doc, nil
+}
+
+var cargoSections = []string{"dependencies", "dev-dependencies", "build-dependencies"}
+
+// parseCargoToml reads dependency tables from a Cargo.toml, including
+// workspace and target-specific tables.
+func parseCargoToml(src []byte) (map[string]string, any, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(src), &doc); err != nil {
+		return nil, nil, err
+	}
+	deps := make(map[string]string)
+	take := func(table map[string]any) {
+		for _, section := range cargoSections {
+			if m, ok := table[section].(map[string]any); ok {
+				for name, v := range m {
+					deps[name] = cargoVersion(v)
+				}
+			}
+			delete(table, section)
+		}
+	}
+	take(doc)
+	if ws, ok := doc["workspace"].(map[string]any); ok {
+		take(ws)
+	}
+	if targets, ok := doc["target"].(map[string]any); ok {
+		for _, t := range targets {
+			if table, ok := t.(map[string]any); ok {
+				take(table)
+			}
+		}
+	}
+	return deps, doc, nil
+}
+
+// cargoVersion returns the version of a Cargo dependency given as a string
+// or as a table.
+func cargoVersion(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		for _, key := range []string{"version", "rev", "tag", "branch", "git", "path"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+		if ws, ok := v["workspace"].(bool); ok && ws {
+			return "workspace"
+		}
+	}
+	return ""
+}
+
+// parsePyproject reads PEP 621 and Poetry dependencies from a
+// pyproject.toml.
+func parsePyproject(src []byte) (map[string]string, any, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(src), &doc); err != nil {
+		return nil, nil, err
+	}
+	deps := make(map[string]string)
+	if project, ok := doc["project"].(map[string]any); ok {
+		addRequirements(deps, project["dependencies"])
+		delete(project, "dependencies")
+		if groups, ok := project["optional-dependencies"].(map[string]any); ok {
+			for _, list := range groups {
+				addRequirements(deps, list)
+			}
+		}
+		delete(project, "optional-dependencies")
+	}
+	if tool, ok := doc["tool"].(map[string]any); ok {
+		if poetry, ok := tool["poetry"].(map[string]any); ok {
+			tables := []any{poetry["dependencies"], poetry["dev-dependencies"]}
+			delete(poetry, "dependencies")
+			delete(poetry, "dev-dependencies")
+			if groups, ok := poetry["group"].(map[string]any); ok {
+				for _, g := range groups {
+					if group, ok := g.(map[string]any); ok {
+						tables = append(tables, group["dependencies"])
+						delete(group, "dependencies")
+					}
+				}
+			}
+			for _, t := range tables {
+				table, _ := t.(map[string]any)
+				for name, v := range table {
+					if name != "python" {
+						deps[normalizePythonName(name)] = cargoVersion(v)
+					}
+				}
+			}
+		}
+	}
+	return deps, doc, nil
+}
+
+func addRequirements(deps map[string]string, list any) {
+	items, _ := list.([]any)
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			if name, version := parseRequirement(s); name != "" {
+				deps[name] = version
+			}
+		}
+	}
+}
+
+// parseRequirements reads a pip requirements file. Option lines such as
+// "-r other.txt" are kept as non-dependency content.
+func parseRequirements(src []byte) (map[string]string, any, error) {
+	deps := make(map[string]string)
+	var rest []string
+	for _, line := range strings.Split(string(src), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "-") {
+			rest = append(rest, line)
+			continue
+		}
+		if name, version := parseRequirement(line); name != "" {
+			deps[name] = version
+		}
+	}
+	return deps, rest, nil
+}
+
+var requirementName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+// parseRequirement splits a PEP 508 requirement into its normalized name
+// and version specifier, dropping extras and environment markers.
+func parseRequirement(req string) (string, string) {
+	if i := strings.Index(req, ";"); i >= 0 {
+		req = req[:i]
+	}
+	req = strings.TrimSpace(req)
+	name := requirementName.FindString(req)
+	if name == "" {
+		return "", ""
+	}
+	spec := strings.TrimSpace(req[len(name):])
+	if strings.HasPrefix(spec, "[") {
+		if i := strings.Index(spec, "]"); i >= 0 {
+			spec = strings.TrimSpace(spec[i+1:])
+		}
+	}
+	spec = strings.TrimPrefix(spec, "==")
+	return normalizePythonName(name), strings.TrimSpace(spec)
+}
+
+// normalizePythonName normalizes a Python package name as PEP 503 does.
+func normalizePythonName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
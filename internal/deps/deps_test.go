@@ -0,0 +1,160 @@
+package deps
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareGoMod(t *testing.T) {
+	oldSrc := []byte(`module example.com/app
+
+go 1.22
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	golang.org/x/text v0.15.0 // indirect
+	github.com/old/lib v1.0.0
+)
+`)
+	newSrc := []byte(`module example.com/app
+
+go 1.22
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	golang.org/x/text v0.14.0 // indirect
+)
+
+require github.com/new/lib v0.2.0
+`)
+	changes, onlyDeps, err := Compare("go.mod", oldSrc, newSrc)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	want := []Change{
+		{Manifest: "go.mod", Name: "github.com/BurntSushi/toml", Action: Upgraded, From: "v1.3.2", To: "v1.4.0"},
+		{Manifest: "go.mod", Name: "github.com/new/lib", Action: Added, To: "v0.2.0"},
+		{Manifest: "go.mod", Name: "github.com/old/lib", Action: Removed, From: "v1.0.0"},
+		{Manifest: "go.mod", Name: "golang.org/x/text", Action: Downgraded, From: "v0.15.0", To: "v0.14.0"},
+	}
+	if !slices.Equal(changes, want) {
+		t.Fatalf("Compare =\n%+v\nwant\n%+v", changes, want)
+	}
+	if !onlyDeps {
+		t.Fatal("expected a dependency-only change")
+	}
+
+	_, onlyDeps, err = Compare("go.mod", oldSrc, []byte("module example.com/app\n\ngo 1.23\n"))
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if onlyDeps {
+		t.Fatal("changing the go directive is not a dependency-only change")
+	}
+}
+
+func TestComparePackageJSON(t *testing.T) {
+	oldSrc := []byte(`{"name": "app", "dependencies": {"react": "^18.2.0"}, "devDependencies": {"jest": "^29.0.0"}}`)
+	newSrc := []byte(`{"name": "app", "dependencies": {"react": "^18.3.1"}, "devDependencies": {"jest": "^29.0.0", "vitest": "^1.0.0"}}`)
+	changes, onlyDeps, err := Compare("web/package.json", oldSrc, newSrc)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Describe() != "bump react from ^18.2.0 to ^18.3.1" || changes[1].Describe() != "add vitest ^1.0.0" {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if !onlyDeps {
+		t.Fatal("expected a dependency-only change")
+	}
+
+	_, onlyDeps, _ = Compare("package.json", oldSrc, []byte(`{"name": "app", "scripts": {"test": "jest"}, "dependencies": {"react": "^18.2.0"}, "devDependencies": {"jest": "^29.0.0"}}`))
+	if onlyDeps {
+		t.Fatal("adding scripts is not a dependency-only change")
+	}
+}
+
+func TestCompareCargoToml(t *testing.T) {
+	oldSrc := []byte("[package]\nname = \"app\"\n\n[dependencies]\nserde = \"1.0.190\"\ntokio = { version = \"1.33\", features = [\"full\"] }\n")
+	newSrc := []byte("[package]\nname = \"app\"\n\n[dependencies]\nserde = \"1.0.195\"\ntokio = { version = \"1.35\", features = [\"full\"] }\n\n[dev-dependencies]\ninsta = \"1\"\n")
+	changes, onlyDeps, err := Compare("Cargo.toml", oldSrc, newSrc)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Describe())
+	}
+	want := []string{"add insta 1", "bump serde from 1.0.190 to 1.0.195", "bump tokio from 1.33 to 1.35"}
+	if !slices.Equal(got, want) || !onlyDeps {
+		t.Fatalf("changes = %q, onlyDeps = %v", got, onlyDeps)
+	}
+}
+
+func TestComparePython(t *testing.T) {
+	oldSrc := []byte("[project]\nname = \"app\"\ndependencies = [\"requests>=2.30\", \"Flask[async]==3.0.0; python_version >= '3.9'\"]\n")
+	newSrc := []byte("[project]\nname = \"app\"\ndependencies = [\"requests>=2.31\", \"flask[async]==3.0.2; python_version >= '3.9'\"]\n")
+	changes, onlyDeps, err := Compare("pyproject.toml", oldSrc, newSrc)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Describe() != "bump flask from 3.0.0 to 3.0.2" || changes[1].Describe() != "bump requests from >=2.30 to >=2.31" || !onlyDeps {
+		t.Fatalf("changes = %+v, onlyDeps = %v", changes, onlyDeps)
+	}
+
+	changes, onlyDeps, err = Compare("requirements-dev.txt", []byte("# tools\npytest==7.4.0\n-r requirements.txt\n"), []byte("pytest==8.0.0 # latest\n-r requirements.txt\n"))
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Action != Upgraded || !onlyDeps {
+		t.Fatalf("changes = %+v, onlyDeps = %v", changes, onlyDeps)
+	}
+}
+
+func TestCompareAddedManifest(t *testing.T) {
+	changes, onlyDeps, err := Compare("go.mod", nil, []byte("module m\n\nrequire example.com/x v1.0.0\n"))
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Action != Added || onlyDeps {
+		t.Fatalf("changes = %+v, onlyDeps = %v", changes, onlyDeps)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		cmp  int
+		ok   bool
+	}{
+		{"v1.2.3", "v1.10.0", -1, true},
+		{"^2.0.0", "^1.9.9", 1, true},
+		{"1.0", "1.0.0", 0, false},
+		{"v1.0.0-rc1", "v1.0.0", 0, false},
+		{"main", "v1.0.0", 0, false},
+	}
+	for _, tt := range tests {
+		cmp, ok := compareVersions(tt.a, tt.b)
+		if cmp != tt.cmp || ok != tt.ok {
+			t.Errorf("compareVersions(%q, %q) = %d, %v, want %d, %v", tt.a, tt.b, cmp, ok, tt.cmp, tt.ok)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	one := []Change{{Manifest: "go.mod", Name: "golang.org/x/text", Action: Upgraded, From: "v0.14.0", To: "v0.15.0"}}
+	if got := Message("conventional", "deps", one); got != "chore(deps): bump golang.org/x/text from v0.14.0 to v0.15.0" {
+		t.Fatalf("Message(conventional) = %q", got)
+	}
+	if got := Message("", "deps", one); got != "Bump golang.org/x/text from v0.14.0 to v0.15.0" {
+		t.Fatalf("Message(default) = %q", got)
+	}
+
+	many := []Change{
+		one[0],
+		{Manifest: "web/package.json", Name: "left-pad", Action: Removed, From: "1.0.0"},
+	}
+	want := "⬆️ update 2 dependencies\n\n- bump golang.org/x/text from v0.14.0 to v0.15.0 (go.mod)\n- remove left-pad (web/package.json)"
+	if got := Message("gitmoji", "deps", many); got != want {
+		t.Fatalf("Message(gitmoji) = %q, want %q", got, want)
+	}
+}
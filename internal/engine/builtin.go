@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"git-ai-commit/internal/message"
+	"git-ai-commit/internal/prompt"
+)
+
+// BuiltinName is the engine name that selects the Builtin engine.
+const BuiltinName = "builtin"
+
+// maxBuiltinBullets bounds the number of bullet points in a message written
+// by the Builtin engine.
+const maxBuiltinBullets = 15
+
+// Builtin is an offline engine that needs no model. It writes a message from
+// the structured summary of the change rather than from the prompt text,
+// styled for the named prompt preset.
+type Builtin struct {
+	Preset string
+	Data   prompt.PromptData
+}
+
+// Generate ignores the prompt and describes the change in b.Data.
+func (b Builtin) Generate(string) (string, error) {
+	files := b.Data.Files
+	if len(files) == 0 {
+		// An empty commit can only be described by the given context.
+		if subject, _, _ := strings.Cut(strings.TrimSpace(b.Data.Context), "\n"); subject != "" {
+			return message.Subject(b.Preset, message.Kind{Type: "chore", Emoji: "🔧"}, subject), nil
+		}
+		return "", fmt.Errorf("builtin engine: no changed files to describe")
+	}
+	kind := builtinKind(files, b.Data.Declarations)
+	if len(b.Data.Scopes) > 0 {
+		kind.Scope = strings.Join(b.Data.Scopes, ",")
+	} else if scope := builtinScope(files); scope != kind.Type {
+		kind.Scope = scope
+	}
+
+	var msg strings.Builder
+	msg.WriteString(message.Subject(b.Preset, kind, builtinSubject(files, b.Data.Declarations)))
+
+	var bullets []string
+	for _, f := range files {
+		bullets = append(bullets, describeFile(f))
+	}
+	for _, d := range b.Data.Declarations {
+		bullets = append(bullets, fmt.Sprintf("%s %s %s.%s", d.Action, d.Kind, d.Package, d.Name))
+	}
+	bullets = append(bullets, b.Data.Dependencies...)
+	if len(bullets) > 1 {
+		msg.WriteString("\n\n")
+		for i, bullet := range bullets {
+			if i == maxBuiltinBullets {
+				fmt.Fprintf(&msg, "- and %d more\n", len(bullets)-i)
+				break
+			}
+			msg.WriteString("- " + bullet + "\n")
+		}
+	}
+	if len(b.Data.Breaking) > 0 {
+		msg.WriteString("\nBREAKING CHANGE: " + strings.Join(b.Data.Breaking, "; ") + "\n")
+	}
+	return strings.TrimSpace(msg.String()), nil
+}
+
+func describeFile(f prompt.FileChange) string {
+	switch f.Status {
+	case "renamed", "copied":
+		return fmt.Sprintf("%s %s to %s", f.Status, f.OldPath, f.Path)
+	case "added", "deleted":
+		return f.Status + " " + f.Path
+	}
+	if f.Binary {
+		return f.Status + " " + f.Path
+	}
+	return fmt.Sprintf("%s %s (+%d -%d)", f.Status, f.Path, f.Added, f.Deleted)
+}
+
+// builtinKind classifies the change for presets that need a type or emoji.
+func builtinKind(files []prompt.FileChange, decls []prompt.DeclChange) message.Kind {
+	switch {
+	case allFiles(files, isDocFile):
+		return message.Kind{Type: "docs", Emoji: "📝"}
+	case allFiles(files, isTestFile):
+		return message.Kind{Type: "test", Emoji: "✅"}
+	case allFiles(files, isCIFile):
+		return message.Kind{Type: "ci", Emoji: "👷"}
+	case allFiles(files, func(f prompt.FileChange) bool { return f.Status == "deleted" }):
+		return message.Kind{Type: "chore", Emoji: "🔥"}
+	case slices.ContainsFunc(decls, func(d prompt.DeclChange) bool { return d.Action == "added" }),
+		slices.ContainsFunc(files, func(f prompt.FileChange) bool { return f.Status == "added" && !isTestFile(f) && !isDocFile(f) }):
+		return message.Kind{Type: "feat", Emoji: "✨"}
+	}
+	return message.Kind{Type: "refactor", Emoji: "♻️"}
+}
+
+// builtinSubject names the most significant declarations touched, falling
+// back to the files.
+func builtinSubject(files []prompt.FileChange, decls []prompt.DeclChange) string {
+	for _, action := range []struct{ name, verb string }{{"added", "add"}, {"removed", "remove"}, {"modified", "update"}} {
+		var names []string
+		for _, d := range decls {
+			if d.Action == action.name && d.Kind != "field" {
+				names = append(names, d.Name)
+			}
+		}
+		if len(names) > 0 {
+			return action.verb + " " + joinNames(names)
+		}
+	}
+
+	if len(files) == 1 {
+		f := files[0]
+		switch f.Status {
+		case "added":
+			return "add " + f.Path
+		case "deleted":
+			return "remove " + f.Path
+		case "renamed":
+			return fmt.Sprintf("rename %s to %s", f.OldPath, f.Path)
+		}
+		return "update " + f.Path
+	}
+	verb := "update"
+	switch {
+	case allFiles(files, func(f prompt.FileChange) bool { return f.Status == "added" }):
+		verb = "add"
+	case allFiles(files, func(f prompt.FileChange) bool { return f.Status == "deleted" }):
+		verb = "remove"
+	}
+	if dir := commonDir(files); dir != "" {
+		return fmt.Sprintf("%s %d files in %s", verb, len(files), dir)
+	}
+	return fmt.Sprintf("%s %d files", verb, len(files))
+}
+
+func joinNames(names []string) string {
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return fmt.Sprintf("%s, %s and %d more", names[0], names[1], len(names)-2)
+}
+
+// builtinScope returns the name of the deepest directory containing every
+// changed file, or "" at the repository root.
+func builtinScope(files []prompt.FileChange) string {
+	dir := commonDir(files)
+	if dir == "" {
+		return ""
+	}
+	return path.Base(dir)
+}
+
+func commonDir(files []prompt.FileChange) string {
+	var common []string
+	for i, f := range files {
+		dir := path.Dir(f.Path)
+		if dir == "." {
+			return ""
+		}
+		parts := strings.Split(dir, "/")
+		if i == 0 {
+			common = parts
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	return strings.Join(common, "/")
+}
+
+func allFiles(files []prompt.FileChange, pred func(prompt.FileChange) bool) bool {
+	for _, f := range files {
+		if !pred(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDocFile(f prompt.FileChange) bool {
+	ext := path.Ext(f.Path)
+	return ext == ".md" || ext == ".rst" || ext == ".adoc" || strings.HasPrefix(f.Path, "docs/") || strings.HasPrefix(path.Base(f.Path), "LICENSE")
+}
+
+func isTestFile(f prompt.FileChange) bool {
+	base := path.Base(f.Path)
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.Contains("/"+f.Path, "/testdata/")
+}
+
+func isCIFile(f prompt.FileChange) bool {
+	return strings.HasPrefix(f.Path, ".github/workflows/") || f.Path == ".gitlab-ci.yml" || strings.HasPrefix(f.Path, ".circleci/")
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"testing"
+
+	"git-ai-commit/internal/prompt"
+)
+
+func TestBuiltinGenerateDeclarations(t *testing.T) {
+	b := Builtin{
+		Preset: "conventional",
+		Data: prompt.PromptData{
+			Files: []prompt.FileChange{
+				{Path: "internal/app/app.go", Status: "modified", Added: 20, Deleted: 3},
+				{Path: "internal/app/options.go", Status: "added", Added: 30},
+			},
+			Declarations: []prompt.DeclChange{
+				{Package: "app", Kind: "type", Name: "Options", Action: "added"},
+				{Package: "app", Kind: "func", Name: "Run", Action: "modified"},
+			},
+		},
+	}
+	got, err := b.Generate("")
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	want := "feat(app): add Options\n\n" +
+		"- modified internal/app/app.go (+20 -3)\n" +
+		"- added internal/app/options.go\n" +
+		"- added type app.Options\n" +
+		"- modified func app.Run"
+	if got != want {
+		t.Fatalf("Generate =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuiltinGeneratePresets(t *testing.T) {
+	data := prompt.PromptData{Files: []prompt.FileChange{
+		{Path: "docs/usage.md", Status: "modified", Added: 4, Deleted: 1},
+	}}
+	tests := map[string]string{
+		"":             "Update docs/usage.md",
+		"conventional": "docs: update docs/usage.md",
+		"gitmoji":      "📝 update docs/usage.md",
+	}
+	for preset, want := range tests {
+		got, err := Builtin{Preset: preset, Data: data}.Generate("")
+		if err != nil {
+			t.Fatalf("Generate error: %v", err)
+		}
+		if got != want {
+			t.Errorf("Generate(%q) = %q, want %q", preset, got, want)
+		}
+	}
+}
+
+func TestBuiltinGenerateFilesAndBreaking(t *testing.T) {
+	b := Builtin{
+		Preset: "karma",
+		Data: prompt.PromptData{
+			Files: []prompt.FileChange{
+				{Path: "lib/a.go", Status: "deleted", Deleted: 10},
+				{Path: "cmd/b.go", Status: "deleted", Deleted: 5},
+			},
+			Breaking: []string{"removed exported func lib.Parse"},
+		},
+	}
+	got, err := b.Generate("")
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	want := "chore: remove 2 files\n\n" +
+		"- deleted lib/a.go\n" +
+		"- deleted cmd/b.go\n\n" +
+		"BREAKING CHANGE: removed exported func lib.Parse"
+	if got != want {
+		t.Fatalf("Generate =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuiltinGenerateNoFiles(t *testing.T) {
+	if _, err := (Builtin{}).Generate(""); err == nil {
+		t.Fatal("expected error without files")
+	}
+	got, err := (Builtin{Preset: "conventional", Data: prompt.PromptData{Context: "trigger a CI run\n\nThe runner was down."}}).Generate("")
+	if err != nil || got != "chore: trigger a CI run" {
+		t.Fatalf("Generate for an empty commit = %q, %v", got, err)
+	}
+}
+
+func TestBuiltinGenerateUsesConfiguredScopes(t *testing.T) {
+	b := Builtin{
+		Preset: "karma",
+		Data: prompt.PromptData{
+			Files:  []prompt.FileChange{{Path: "cmd/git-ai-commit/main.go", Status: "modified", Added: 1, Deleted: 1}},
+			Scopes: []string{"cli"},
+		},
+	}
+	got, err := b.Generate("")
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	if got != "refactor(cli): update cmd/git-ai-commit/main.go" {
+		t.Fatalf("Generate = %q", got)
+	}
+}
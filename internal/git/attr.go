@@ -0,0 +1,112 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// AttrMode values accepted by the ai-commit gitattribute.
+const (
+	AttrModeExclude = "exclude" // drop the file from the diff
+	AttrModeSummary = "summary" // replace the file's hunks with a one-line summary
+	AttrModeFull    = "full"    // always send the file, bypassing patterns and truncation
+)
+
+// attrNames lists the gitattributes queried by CheckAttr.
+var attrNames = []string{"linguist-generated", "linguist-vendored", "diff", "ai-commit"}
+
+// Attributes holds the gitattributes that affect how a file is presented to
+// the engine.
+type Attributes struct {
+	Generated bool   // linguist-generated is set
+	Vendored  bool   // linguist-vendored is set
+	NoDiff    bool   // diff is unset (-diff)
+	Mode      string // value of the ai-commit attribute, if any
+}
+
+// CheckAttr reads the attributes relevant to diff filtering for the given
+// repository-relative paths from the index. Paths without any relevant
+// attribute are omitted from the returned map.
+func CheckAttr(paths []string) (map[string]Attributes, error) {
+	attrs := make(map[string]Attributes)
+	if len(paths) == 0 {
+		return attrs, nil
+	}
+	root, err := RepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"check-attr", "-z", "--cached", "--stdin"}, attrNames...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git check-attr failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Output is a sequence of <path> NUL <attribute> NUL <info> NUL records.
+	fields := strings.Split(strings.TrimSuffix(stdout.String(), "\x00"), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		path, name, value := fields[i], fields[i+1], fields[i+2]
+		if value == "unspecified" {
+			continue
+		}
+		a := attrs[path]
+		switch name {
+		case "linguist-generated":
+			a.Generated = attrIsSet(value)
+		case "linguist-vendored":
+			a.Vendored = attrIsSet(value)
+		case "diff":
+			a.NoDiff = value == "unset"
+		case "ai-commit":
+			a.Mode = value
+		}
+		if a != (Attributes{}) {
+			attrs[path] = a
+		}
+	}
+	return attrs, nil
+}
+
+func attrIsSet(value string) bool {
+	return value == "set" || value == "true"
+}
+
+// filterMode returns the effective ai-commit mode for a file and the reason
+// reported for it. An empty mode means the regular pattern and truncation
+// rules apply.
+func (a Attributes) filterMode() (string, string) {
+	switch a.Mode {
+	case AttrModeExclude, AttrModeSummary, AttrModeFull:
+		return a.Mode, "ai-commit=" + a.Mode
+	}
+	switch {
+	case a.Generated:
+		return AttrModeExclude, "linguist-generated"
+	case a.Vendored:
+		return AttrModeExclude, "linguist-vendored"
+	case a.NoDiff:
+		return AttrModeSummary, "-diff"
+	}
+	return "", ""
+}
+
+// RepoRoot returns the top-level directory of the working tree.
+func RepoRoot() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
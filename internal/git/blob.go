@@ -0,0 +1,99 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// headBytes caps how much of each file FileHeads keeps.
+const headBytes = 32 << 10
+
+// FileHeads returns up to n leading lines of the version of each path being
+// committed: the staged version, or with amend the one in HEAD. Paths
+// without such a version, such as deleted files, are omitted from the
+// returned map.
+func FileHeads(paths []string, amend bool, n int) (map[string]string, error) {
+	heads := make(map[string]string)
+	rev := ""
+	if amend {
+		rev = "HEAD"
+	}
+	var input strings.Builder
+	var requested []string
+	for _, p := range paths {
+		// cat-file --batch reads one object name per line.
+		if strings.Contains(p, "\n") {
+			continue
+		}
+		fmt.Fprintf(&input, "%s:%s\n", rev, p)
+		requested = append(requested, p)
+	}
+	if len(requested) == 0 {
+		return heads, nil
+	}
+	root, err := RepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(input.String())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git cat-file failed: %v", err)
+	}
+	readErr := readHeads(bufio.NewReader(stdout), requested, n, heads)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git cat-file failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("git cat-file failed: %v", readErr)
+	}
+	return heads, nil
+}
+
+// readHeads reads one "git cat-file --batch" record per requested path and
+// stores the leading n lines of each object found in heads.
+func readHeads(r *bufio.Reader, paths []string, n int, heads map[string]string) error {
+	for _, p := range paths {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		// "<oid> <type> <size>", or "<name> missing" for absent objects.
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected object header %q", strings.TrimSpace(header))
+		}
+		data := make([]byte, min(size, headBytes))
+		if _, err := io.ReadFull(r, data); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(io.Discard, r, size-int64(len(data))+1); err != nil {
+			return err
+		}
+		if fields[1] != "blob" {
+			continue
+		}
+		lines := strings.SplitAfterN(string(data), "\n", n+1)
+		if len(lines) > n {
+			lines = lines[:n]
+		}
+		heads[p] = strings.Join(lines, "")
+	}
+	return nil
+}
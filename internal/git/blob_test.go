@@ -0,0 +1,40 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileHeads(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "long.txt", "one\ntwo\nthree\nfour\n")
+		writeFile(t, repo, "gone.txt", "bye\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "initial")
+		runGit(t, repo, "rm", "-q", "gone.txt")
+		writeFile(t, repo, "long.txt", "ONE\ntwo\nthree\nfour\n")
+		runGit(t, repo, "add", "long.txt")
+		// Unstaged edits are not what is being committed.
+		if err := os.WriteFile(filepath.Join(repo, "long.txt"), []byte("unstaged\n"), 0o644); err != nil {
+			t.Fatalf("write long.txt: %v", err)
+		}
+
+		heads, err := FileHeads([]string{"long.txt", "gone.txt"}, false, 2)
+		if err != nil {
+			t.Fatalf("FileHeads error: %v", err)
+		}
+		if len(heads) != 1 || heads["long.txt"] != "ONE\ntwo\n" {
+			t.Fatalf("staged heads = %q", heads)
+		}
+
+		heads, err = FileHeads([]string{"long.txt", "gone.txt"}, true, 1)
+		if err != nil {
+			t.Fatalf("FileHeads error: %v", err)
+		}
+		if heads["long.txt"] != "one\n" || heads["gone.txt"] != "bye\n" {
+			t.Fatalf("HEAD heads = %q", heads)
+		}
+	})
+}
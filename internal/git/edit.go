@@ -0,0 +1,204 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// directiveKeyword starts a directive comment line, such as
+// "# ai: mention the cache invalidation", asking for a new message.
+const directiveKeyword = "ai:"
+
+// Reviser rewrites message following the instructions of the directives
+// left in the editor. An empty instruction asks for a new attempt.
+type Reviser func(message string, instructions []string) (string, error)
+
+// editAndCommit opens message in the user's editor and commits the result.
+// While the saved buffer contains directives and revise is not nil, the
+// message is revised and the editor opened again. When revising fails, the
+// editor is opened again on the saved message with the error as a comment.
+func editAndCommit(message string, opts CommitOptions, revise Reviser) error {
+	editor, err := gitOutput("var", "GIT_EDITOR")
+	if err != nil {
+		return err
+	}
+	editor = strings.TrimSpace(editor)
+	path, err := gitOutput("rev-parse", "--git-path", "COMMIT_EDITMSG")
+	if err != nil {
+		return err
+	}
+	path = strings.TrimSpace(path)
+
+	var diff string
+	if opts.Verbose {
+		if opts.Amend {
+			diff, err = LastCommitDiff(DiffOptions{})
+		} else {
+			diff, err = StagedDiff(DiffOptions{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var failure error
+	for {
+		commentChar := opts.CommentChar
+		if commentChar == "" {
+			commentChar = "#"
+		}
+		bufferOpts := opts
+		if failure != nil {
+			bufferOpts.Comment = fmt.Sprintf("Regenerating the message failed: %v", failure)
+			if opts.Comment != "" {
+				bufferOpts.Comment += "\n\n" + opts.Comment
+			}
+		}
+		if err := os.WriteFile(path, []byte(editorBuffer(message, commentChar, bufferOpts, diff, revise != nil)), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("editor %q failed: %v", editor, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		edited := cutScissors(string(data), commentChar)
+		instructions := Directives(edited, commentChar)
+		if len(instructions) == 0 || revise == nil {
+			message, err = cleanupMessage(edited, commentChar, opts.Cleanup)
+			if err != nil {
+				return err
+			}
+			// The message is clean; git must not strip it again.
+			opts.Edit = false
+			opts.Cleanup = "verbatim"
+			return CommitWithMessage(message, opts, nil)
+		}
+		current, err := cleanupMessage(edited, commentChar, "strip")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "regenerating the message: %s\n", strings.Join(instructions, "; "))
+		revised, err := revise(current, instructions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "regenerating the message failed: %v\n", err)
+			message, failure = current, err
+			continue
+		}
+		message, failure = revised, nil
+	}
+}
+
+// editorBuffer lays out the editor buffer: the message, then the comment
+// block and, for verbose commits, the diff. Cleanup modes that keep comment
+// lines get the comments below a scissors line, which is always cut.
+func editorBuffer(message, commentChar string, opts CommitOptions, diff string, revisable bool) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(message, "\n"))
+	b.WriteString("\n\n")
+
+	comment := strings.TrimRight(opts.Comment, "\n")
+	if revisable {
+		if comment != "" {
+			comment += "\n\n"
+		}
+		comment += fmt.Sprintf("To regenerate the message, add a line such as\n\"%s %s make it mention the cache\" and save.", commentChar, directiveKeyword)
+	}
+	scissors := false
+	switch opts.Cleanup {
+	case "", "default", "strip":
+	default:
+		if comment != "" {
+			writeScissors(&b, commentChar)
+			scissors = true
+		}
+	}
+	if comment != "" {
+		writeComment(&b, comment, commentChar)
+	}
+	if diff != "" {
+		if !scissors {
+			writeScissors(&b, commentChar)
+		}
+		b.WriteString(diff)
+	}
+	return b.String()
+}
+
+func writeScissors(b *strings.Builder, commentChar string) {
+	fmt.Fprintf(b, "%s %s\n", commentChar, scissorsLine)
+	writeComment(b, "Do not modify or remove the line above.\nEverything below it will be ignored.", commentChar)
+}
+
+func writeComment(b *strings.Builder, text, commentChar string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			b.WriteString(commentChar + "\n")
+		} else {
+			b.WriteString(commentChar + " " + line + "\n")
+		}
+	}
+}
+
+// cutScissors removes the scissors line and everything below it.
+func cutScissors(buffer, commentChar string) string {
+	marker := commentChar + " " + scissorsLine + "\n"
+	if strings.HasPrefix(buffer, marker) {
+		return ""
+	}
+	if i := strings.Index(buffer, "\n"+marker); i >= 0 {
+		return buffer[:i+1]
+	}
+	return buffer
+}
+
+// Directives returns the instructions of the directive comment lines in an
+// edited buffer, such as "# ai: make it shorter". A directive without an
+// instruction yields "". Cut the scissors line first so the diff is not
+// searched.
+func Directives(buffer, commentChar string) []string {
+	var instructions []string
+	for _, line := range strings.Split(buffer, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), commentChar)
+		if !ok {
+			continue
+		}
+		if instruction, ok := strings.CutPrefix(strings.TrimSpace(rest), directiveKeyword); ok {
+			instructions = append(instructions, strings.TrimSpace(instruction))
+		}
+	}
+	return instructions
+}
+
+// cleanupMessage applies a git commit cleanup mode to an edited buffer with
+// git stripspace. The scissors line must already have been cut.
+func cleanupMessage(buffer, commentChar, mode string) (string, error) {
+	var args []string
+	switch mode {
+	case "verbatim":
+		return buffer, nil
+	case "", "default", "strip":
+		args = []string{"-c", "core.commentChar=" + commentChar, "stripspace", "--strip-comments"}
+	default:
+		args = []string{"stripspace"}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(buffer)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git stripspace failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
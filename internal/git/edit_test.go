@@ -0,0 +1,130 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEditorBuffer(t *testing.T) {
+	opts := CommitOptions{Comment: "Engine: claude\n\nfile.go | +1 -0"}
+	want := "Fix it\n\n# Engine: claude\n#\n# file.go | +1 -0\n"
+	if got := editorBuffer("Fix it\n", "#", opts, "", false); got != want {
+		t.Errorf("editorBuffer(strip) = %q, want %q", got, want)
+	}
+
+	opts.Cleanup = "whitespace"
+	got := editorBuffer("Fix it", ";", opts, "", true)
+	if !strings.HasPrefix(got, "Fix it\n\n; "+scissorsLine+"\n") || !strings.Contains(got, "; Engine: claude\n") {
+		t.Errorf("editorBuffer(whitespace) = %q, want comments below the scissors line", got)
+	}
+	if !strings.Contains(got, `"; ai: make it mention the cache"`) {
+		t.Errorf("editorBuffer(revisable) = %q, want directive help", got)
+	}
+
+	got = editorBuffer("Fix it", "#", CommitOptions{}, "diff --git a/x b/x\n", false)
+	if want := "Fix it\n\n# " + scissorsLine + "\n"; !strings.HasPrefix(got, want) || !strings.HasSuffix(got, "diff --git a/x b/x\n") {
+		t.Errorf("editorBuffer(verbose) = %q", got)
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	buffer := "Fix it\n\n# ai: mention the cache\n#ai:\n  # ai:shorter  \n# not ai: a directive\nai: neither\n"
+	got := Directives(buffer, "#")
+	want := []string{"mention the cache", "", "shorter"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Directives = %q, want %q", got, want)
+	}
+}
+
+func TestCutScissors(t *testing.T) {
+	buffer := "Fix it\n\n# " + scissorsLine + "\n# Engine: claude\n"
+	if got := cutScissors(buffer, "#"); got != "Fix it\n\n" {
+		t.Errorf("cutScissors = %q", got)
+	}
+	if got := cutScissors("Fix it\n", "#"); got != "Fix it\n" {
+		t.Errorf("cutScissors without scissors = %q", got)
+	}
+}
+
+func TestCommitWithMessageRevise(t *testing.T) {
+	repo := setupRepo(t)
+	// The editor adds a directive the first time it runs only.
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	marker := filepath.Join(t.TempDir(), "edited")
+	script := "#!/bin/sh\nif [ ! -e " + marker + " ]; then\n  touch " + marker + "\n  echo '# ai: mention the greeting' >> \"$1\"\nfi\n"
+	if err := os.WriteFile(editor, []byte(script), 0o755); err != nil {
+		t.Fatalf("write editor: %v", err)
+	}
+	t.Setenv("GIT_EDITOR", editor)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "file.txt", "hello")
+		runGit(t, repo, "add", "file.txt")
+
+		var revisions [][]string
+		var previous string
+		revise := func(message string, instructions []string) (string, error) {
+			previous = message
+			revisions = append(revisions, instructions)
+			return "Add greeting file\n", nil
+		}
+		if err := CommitWithMessage("Add file", CommitOptions{Edit: true, Comment: "Generated by builtin"}, revise); err != nil {
+			t.Fatalf("CommitWithMessage error: %v", err)
+		}
+		if len(revisions) != 1 || !slices.Equal(revisions[0], []string{"mention the greeting"}) {
+			t.Fatalf("revisions = %q", revisions)
+		}
+		if previous != "Add file\n" {
+			t.Fatalf("revised message = %q", previous)
+		}
+		msg, err := CommitMessage("HEAD")
+		if err != nil {
+			t.Fatalf("CommitMessage error: %v", err)
+		}
+		if msg != "Add greeting file" {
+			t.Fatalf("commit message = %q", msg)
+		}
+	})
+}
+
+func TestCommitWithMessageReviseFailure(t *testing.T) {
+	repo := setupRepo(t)
+	// The editor adds a directive the first time it runs and saves a copy
+	// of the buffer it is given the second time.
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	marker := filepath.Join(t.TempDir(), "edited")
+	second := filepath.Join(t.TempDir(), "second")
+	script := "#!/bin/sh\nif [ ! -e " + marker + " ]; then\n  touch " + marker + "\n  echo '# ai: mention the greeting' >> \"$1\"\nelse\n  cp \"$1\" " + second + "\nfi\n"
+	if err := os.WriteFile(editor, []byte(script), 0o755); err != nil {
+		t.Fatalf("write editor: %v", err)
+	}
+	t.Setenv("GIT_EDITOR", editor)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "file.txt", "hello")
+		runGit(t, repo, "add", "file.txt")
+
+		revise := func(message string, instructions []string) (string, error) {
+			return "", errors.New("engine timed out")
+		}
+		if err := CommitWithMessage("Add file", CommitOptions{Edit: true}, revise); err != nil {
+			t.Fatalf("CommitWithMessage error: %v", err)
+		}
+		data, err := os.ReadFile(second)
+		if err != nil {
+			t.Fatalf("editor was not reopened: %v", err)
+		}
+		if !strings.HasPrefix(string(data), "Add file\n") || !strings.Contains(string(data), "# Regenerating the message failed: engine timed out\n") {
+			t.Fatalf("reopened buffer = %q", data)
+		}
+		msg, err := CommitMessage("HEAD")
+		if err != nil {
+			t.Fatalf("CommitMessage error: %v", err)
+		}
+		if msg != "Add file" {
+			t.Fatalf("commit message = %q", msg)
+		}
+	})
+}
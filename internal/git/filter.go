@@ -3,8 +3,10 @@ package git
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -26,11 +28,41 @@ func DefaultExcludePatterns() []string {
 	}
 }
 
+// DefaultGeneratedHeaderPatterns returns the built-in regexes matching header
+// lines of generated files.
+func DefaultGeneratedHeaderPatterns() []string {
+	return []string{
+		`^// Code generated .* DO NOT EDIT\.$`,
+		`^# Generated by the protocol buffer compiler\. +DO NOT EDIT!$`,
+		`^\s*(//|#|/?\*)\s*@generated\b`,
+	}
+}
+
+// GeneratedHeaderLines is the number of leading lines of a file inspected
+// for a generated-file header.
+const GeneratedHeaderLines = 20
+
 // Options holds filtering configuration.
 type Options struct {
 	MaxFileLines    int      // Maximum lines per file (0 = no limit)
 	ExcludePatterns []string // Glob patterns for files to exclude
 	ExcludeFiles    []string // Exact file paths to exclude
+
+	// Attributes holds gitattributes keyed by file path (see CheckAttr).
+	Attributes map[string]Attributes
+
+	// FunctionContext reports that the diff was generated with
+	// --function-context; truncation then budgets changed lines only.
+	FunctionContext bool
+
+	// GeneratedHeaders are matched against the leading lines of added and
+	// modified files; a match excludes the file as generated.
+	GeneratedHeaders []*regexp.Regexp
+
+	// Heads holds the leading lines of each file's new version keyed by
+	// path (see FileHeads). Without an entry, only a hunk at the top of the
+	// file is checked for a generated header.
+	Heads map[string]string
 }
 
 // Result holds the filtering outcome.
@@ -39,6 +71,9 @@ type Result struct {
 	Truncated      bool     // True if any file was truncated
 	TruncatedFiles []string // List of truncated file paths
 	ExcludedFiles  []string // List of excluded file paths
+
+	SummarizedFiles []string          // Files whose hunks were replaced by a summary
+	Reasons         map[string]string // Why a file was excluded or summarized, when not by pattern
 }
 
 // Filter filters a unified diff according to the given options.
@@ -71,15 +106,39 @@ func Filter(diff string, opts Options) Result {
 			continue
 		}
 
+		// Apply gitattributes overrides
+		mode, reason := opts.Attributes[fileName].filterMode()
+		switch mode {
+		case AttrModeExclude:
+			result.ExcludedFiles = append(result.ExcludedFiles, fileName)
+			result.addReason(fileName, reason)
+			continue
+		case AttrModeSummary:
+			result.SummarizedFiles = append(result.SummarizedFiles, fileName)
+			result.addReason(fileName, reason)
+			filteredParts = append(filteredParts, summarizeFileDiff(content, fileName))
+			continue
+		case AttrModeFull:
+			filteredParts = append(filteredParts, content)
+			continue
+		}
+
 		// Check exclusion patterns
-		if matchesAnyPattern(fileName, opts.ExcludePatterns) {
+		if MatchesAnyPattern(fileName, opts.ExcludePatterns) {
+			result.ExcludedFiles = append(result.ExcludedFiles, fileName)
+			continue
+		}
+
+		// Check generated-file headers
+		if isGenerated(fileName, content, opts) {
 			result.ExcludedFiles = append(result.ExcludedFiles, fileName)
+			result.addReason(fileName, "generated header")
 			continue
 		}
 
 		// Apply line limit if configured
 		if opts.MaxFileLines > 0 {
-			truncated, newContent := truncateFileDiff(content, opts.MaxFileLines, fileName)
+			truncated, newContent := truncateFileDiff(content, opts.MaxFileLines, fileName, opts.FunctionContext)
 			if truncated {
 				result.Truncated = true
 				result.TruncatedFiles = append(result.TruncatedFiles, fileName)
@@ -94,6 +153,24 @@ func Filter(diff string, opts Options) Result {
 	return result
 }
 
+func (r *Result) addReason(fileName, reason string) {
+	if r.Reasons == nil {
+		r.Reasons = make(map[string]string)
+	}
+	r.Reasons[fileName] = reason
+}
+
+// DiffFiles returns the sorted list of file paths touched by a unified diff.
+func DiffFiles(diff string) []string {
+	files := splitDiffByFile(diff)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // splitDiffByFile splits a unified diff into per-file sections.
 // Returns a map of file path to diff content (including header).
 func splitDiffByFile(diff string) map[string]string {
@@ -139,22 +216,73 @@ func splitDiffByFile(diff string) map[string]string {
 
 // extractFilePath extracts the file path from a "diff --git a/path b/path" line.
 func extractFilePath(line string) string {
-	// Format: "diff --git a/path/to/file b/path/to/file"
-	parts := strings.SplitN(line, " ", 4)
-	if len(parts) < 4 {
-		return ""
+	_, newPath := headerPaths(line)
+	return newPath
+}
+
+// headerPaths returns the old and new paths of a "diff --git a/old b/new"
+// header line. Git C-quotes paths with special or non-ASCII characters, as
+// in "a/caf\303\251.txt"; those are unquoted. Unquoted paths may contain
+// spaces, so an unquoted header is split where both halves name the same
+// path, and otherwise before the first " b/".
+func headerPaths(line string) (oldPath, newPath string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if strings.HasPrefix(rest, `"`) {
+		end := quotedEnd(rest)
+		if end < 0 {
+			return "", ""
+		}
+		oldPath = unquotePath(rest[:end])
+		newPath = unquotePath(strings.TrimPrefix(rest[end:], " "))
+	} else if i := strings.LastIndex(rest, ` "b/`); i >= 0 && quotedEnd(rest[i+1:]) == len(rest)-i-1 {
+		oldPath, newPath = rest[:i], unquotePath(rest[i+1:])
+	} else if half := (len(rest) - 1) / 2; len(rest)%2 == 1 && rest[half] == ' ' && rest[2:half] == rest[half+3:] {
+		oldPath, newPath = rest[:half], rest[half+1:]
+	} else if i := strings.Index(rest, " b/"); i >= 0 {
+		oldPath, newPath = rest[:i], rest[i+1:]
+	} else {
+		return "", ""
 	}
-	// Use the b/ path (destination)
-	bPath := parts[3]
-	if strings.HasPrefix(bPath, "b/") {
-		return bPath[2:]
+	return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(newPath, "b/")
+}
+
+// quotedEnd returns the length of the C-quoted string s starts with, or -1.
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
 	}
-	return bPath
+	return -1
 }
 
+// unquotePath undoes git's C-style quoting of a path. Paths that are not
+// quoted are returned unchanged.
+func unquotePath(path string) string {
+	if !strings.HasPrefix(path, `"`) {
+		return path
+	}
+	unquoted, err := strconv.Unquote(path)
+	if err != nil {
+		return path
+	}
+	return unquoted
+}
+
+// functionContextScale bounds the unchanged lines kept for a file when
+// function context is enabled, as a multiple of the per-file line limit.
+const functionContextScale = 4
+
 // truncateFileDiff truncates a file diff to the specified number of lines.
+// When changedOnly is set, as for function-context diffs whose hunks carry
+// whole function bodies, only added and removed lines count toward maxLines
+// and unchanged lines are capped separately at functionContextScale times
+// maxLines.
 // Returns (wasTruncated, newContent).
-func truncateFileDiff(content string, maxLines int, fileName string) (bool, string) {
+func truncateFileDiff(content string, maxLines int, fileName string, changedOnly bool) (bool, string) {
 	lines := strings.Split(content, "\n")
 
 	// Count only the actual diff lines (not headers)
@@ -166,48 +294,73 @@ func truncateFileDiff(content string, maxLines int, fileName string) (bool, stri
 		}
 	}
 
+	counts := func(line string) bool {
+		if changedOnly {
+			return strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")
+		}
+		return true
+	}
+	contextLimit := maxLines * functionContextScale
+
 	// Count diff content lines (after first @@ marker)
-	diffLineCount := 0
+	diffLineCount, contextLineCount := 0, 0
 	for i := headerEnd; i < len(lines); i++ {
 		line := lines[i]
-		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") || strings.HasPrefix(line, " ") {
-			diffLineCount++
+		if isDiffContentLine(line) {
+			if counts(line) {
+				diffLineCount++
+			} else {
+				contextLineCount++
+			}
 		}
 	}
 
-	if diffLineCount <= maxLines {
+	if diffLineCount <= maxLines && contextLineCount <= contextLimit {
 		return false, content
 	}
 
 	// Truncate: keep header + maxLines of diff content
 	var result strings.Builder
-	contentLinesSeen := 0
+	linesSeen, contextSeen, shown := 0, 0, 0
+	within := func() bool {
+		return linesSeen <= maxLines && contextSeen <= contextLimit
+	}
 
+	inHunks := false
 	for _, line := range lines {
-		// Always include header lines (before first @@)
-		if !strings.HasPrefix(line, "@@") && contentLinesSeen == 0 {
+		// Include @@ hunk headers
+		if strings.HasPrefix(line, "@@") {
+			inHunks = true
 			result.WriteString(line)
 			result.WriteString("\n")
 			continue
 		}
 
-		// Include @@ hunk headers
-		if strings.HasPrefix(line, "@@") {
+		// Always include header lines (before first @@)
+		if !inHunks {
 			result.WriteString(line)
 			result.WriteString("\n")
 			continue
 		}
 
 		// Count and potentially truncate content lines
-		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") || strings.HasPrefix(line, " ") {
-			contentLinesSeen++
-			if contentLinesSeen <= maxLines {
+		if isDiffContentLine(line) {
+			counted := counts(line)
+			if counted {
+				linesSeen++
+			} else {
+				contextSeen++
+			}
+			if within() {
+				if counted {
+					shown++
+				}
 				result.WriteString(line)
 				result.WriteString("\n")
 			}
 		} else {
 			// Other lines (like "\ No newline at end of file")
-			if contentLinesSeen <= maxLines {
+			if within() {
 				result.WriteString(line)
 				result.WriteString("\n")
 			}
@@ -215,18 +368,109 @@ func truncateFileDiff(content string, maxLines int, fileName string) (bool, stri
 	}
 
 	// Add truncation marker
-	result.WriteString(fmt.Sprintf("\n... [%s truncated: showing %d of %d lines]\n", fileName, maxLines, diffLineCount))
+	unit := "lines"
+	if changedOnly {
+		unit = "changed lines"
+	}
+	result.WriteString(fmt.Sprintf("\n... [%s truncated: showing %d of %d %s]\n", fileName, shown, diffLineCount, unit))
 
 	return true, result.String()
 }
 
+func isDiffContentLine(line string) bool {
+	return strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") || strings.HasPrefix(line, " ")
+}
+
+// summarizeFileDiff replaces the hunks of a file diff with a single line
+// reporting how many lines were added and removed.
+func summarizeFileDiff(content, fileName string) string {
+	var result strings.Builder
+	added, deleted := 0, 0
+	inHunks := false
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			inHunks = true
+			continue
+		}
+		if !inHunks {
+			result.WriteString(line)
+			result.WriteString("\n")
+			continue
+		}
+		if strings.HasPrefix(line, "+") {
+			added++
+		} else if strings.HasPrefix(line, "-") {
+			deleted++
+		}
+	}
+	result.WriteString(fmt.Sprintf("... [%s summarized: +%d -%d lines]\n", fileName, added, deleted))
+	return result.String()
+}
+
+// isGenerated reports whether the leading lines of a file's new version
+// match any of the generated-file header patterns. They are taken from
+// opts.Heads when it has the file, and otherwise from the diff.
+func isGenerated(fileName, content string, opts Options) bool {
+	if len(opts.GeneratedHeaders) == 0 {
+		return false
+	}
+	head, ok := opts.Heads[fileName]
+	if !ok {
+		head = diffHead(content)
+	}
+	for i, line := range strings.Split(head, "\n") {
+		if i >= GeneratedHeaderLines {
+			break
+		}
+		text := strings.TrimRight(line, "\r")
+		for _, re := range opts.GeneratedHeaders {
+			if re.MatchString(text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// diffHead returns the new-file lines of a hunk that starts at the first
+// line of the new file, or "" when the diff has none.
+func diffHead(content string) string {
+	lines := strings.Split(content, "\n")
+	start := slices.IndexFunc(lines, func(line string) bool { return strings.HasPrefix(line, "@@") })
+	if start < 0 || !hunkStartsAtFirstLine(lines[start]) {
+		return ""
+	}
+	var head []string
+	for _, line := range lines[start+1:] {
+		if strings.HasPrefix(line, "@@") {
+			break
+		}
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, " ") {
+			head = append(head, line[1:])
+		}
+	}
+	return strings.Join(head, "\n")
+}
+
+// hunkStartsAtFirstLine reports whether a "@@ -a,b +c,d @@" header has its
+// new-file range starting at line 1.
+func hunkStartsAtFirstLine(header string) bool {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return false
+	}
+	newRange := fields[2]
+	return newRange == "+1" || strings.HasPrefix(newRange, "+1,")
+}
+
 // containsFile checks if filePath is in the given list of exact paths.
 func containsFile(filePath string, files []string) bool {
 	return slices.Contains(files, filePath)
 }
 
-// matchesAnyPattern checks if the file path matches any of the glob patterns.
-func matchesAnyPattern(filePath string, patterns []string) bool {
+// MatchesAnyPattern checks if the file path matches any of the glob patterns,
+// using the same rules as Filter.
+func MatchesAnyPattern(filePath string, patterns []string) bool {
 	for _, pattern := range patterns {
 		if matchPattern(filePath, pattern) {
 			return true
@@ -1,6 +1,8 @@
 package git
 
 import (
+	"fmt"
+	"regexp"
 	"slices"
 	"strings"
 	"testing"
@@ -461,6 +463,8 @@ func TestExtractFilePath(t *testing.T) {
 		{"diff --git a/file.go b/file.go", "file.go"},
 		{"diff --git a/path/to/file.go b/path/to/file.go", "path/to/file.go"},
 		{"diff --git a/old/name.go b/new/name.go", "new/name.go"},
+		{`diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"`, "café.txt"},
+		{"diff --git a/my file.txt b/my file.txt", "my file.txt"},
 	}
 
 	for _, tt := range tests {
@@ -470,3 +474,285 @@ func TestExtractFilePath(t *testing.T) {
 		}
 	}
 }
+
+func TestFilter_Attributes(t *testing.T) {
+	diff := `diff --git a/api.pb.go b/api.pb.go
+index abc123..def456 100644
+--- a/api.pb.go
++++ b/api.pb.go
+@@ -1,3 +1,4 @@
++generated
+diff --git a/docs/guide.md b/docs/guide.md
+index abc123..def456 100644
+--- a/docs/guide.md
++++ b/docs/guide.md
+@@ -1,3 +1,4 @@
++added line
+-removed line
++another line
+diff --git a/go.sum b/go.sum
+index abc123..def456 100644
+--- a/go.sum
++++ b/go.sum
+@@ -1,3 +1,4 @@
++sum content
+diff --git a/main.go b/main.go
+index abc123..def456 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
++code
+`
+	result := Filter(diff, Options{
+		ExcludePatterns: DefaultExcludePatterns(),
+		Attributes: map[string]Attributes{
+			"api.pb.go":     {Generated: true},
+			"docs/guide.md": {Mode: AttrModeSummary},
+			"go.sum":        {Mode: AttrModeFull},
+		},
+	})
+
+	if !slices.Equal(result.ExcludedFiles, []string{"api.pb.go"}) {
+		t.Errorf("expected excluded [api.pb.go], got %v", result.ExcludedFiles)
+	}
+	if result.Reasons["api.pb.go"] != "linguist-generated" {
+		t.Errorf("expected linguist-generated reason, got %q", result.Reasons["api.pb.go"])
+	}
+	if !slices.Equal(result.SummarizedFiles, []string{"docs/guide.md"}) {
+		t.Errorf("expected summarized [docs/guide.md], got %v", result.SummarizedFiles)
+	}
+	if !strings.Contains(result.Diff, "[docs/guide.md summarized: +2 -1 lines]") {
+		t.Errorf("expected summary marker, got %s", result.Diff)
+	}
+	if strings.Contains(result.Diff, "added line") {
+		t.Error("expected summarized hunks to be dropped")
+	}
+	if !strings.Contains(result.Diff, "sum content") {
+		t.Error("expected ai-commit=full to bypass default exclude patterns")
+	}
+	if !strings.Contains(result.Diff, "+code") {
+		t.Error("expected main.go to be in diff")
+	}
+}
+
+func TestFilter_AttributesExplicitExcludeWins(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index abc123..def456 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
++code
+`
+	result := Filter(diff, Options{
+		ExcludeFiles: []string{"main.go"},
+		Attributes:   map[string]Attributes{"main.go": {Mode: AttrModeFull}},
+	})
+	if !slices.Equal(result.ExcludedFiles, []string{"main.go"}) {
+		t.Errorf("expected main.go to be excluded, got %v", result.ExcludedFiles)
+	}
+}
+
+func TestAttributesFilterMode(t *testing.T) {
+	tests := []struct {
+		attrs      Attributes
+		wantMode   string
+		wantReason string
+	}{
+		{Attributes{}, "", ""},
+		{Attributes{Generated: true}, AttrModeExclude, "linguist-generated"},
+		{Attributes{Vendored: true}, AttrModeExclude, "linguist-vendored"},
+		{Attributes{NoDiff: true}, AttrModeSummary, "-diff"},
+		{Attributes{Generated: true, Mode: AttrModeFull}, AttrModeFull, "ai-commit=full"},
+		{Attributes{Vendored: true, Mode: "bogus"}, AttrModeExclude, "linguist-vendored"},
+	}
+	for _, tt := range tests {
+		mode, reason := tt.attrs.filterMode()
+		if mode != tt.wantMode || reason != tt.wantReason {
+			t.Errorf("%+v.filterMode() = (%q, %q), want (%q, %q)", tt.attrs, mode, reason, tt.wantMode, tt.wantReason)
+		}
+	}
+}
+
+func TestDiffFiles(t *testing.T) {
+	diff := `diff --git a/b.go b/b.go
++b
+diff --git a/a.go b/a.go
++a
+`
+	got := DiffFiles(diff)
+	if !slices.Equal(got, []string{"a.go", "b.go"}) {
+		t.Errorf("DiffFiles = %v", got)
+	}
+}
+
+func TestFilter_GeneratedHeader(t *testing.T) {
+	diff := `diff --git a/api.pb.go b/api.pb.go
+new file mode 100644
+index 0000000..def456
+--- /dev/null
++++ b/api.pb.go
+@@ -0,0 +1,4 @@
++// Code generated by protoc-gen-go. DO NOT EDIT.
++// versions:
++
++package api
+diff --git a/mock.go b/mock.go
+index abc123..def456 100644
+--- a/mock.go
++++ b/mock.go
+@@ -1,4 +1,4 @@
+ // Code generated by MockGen. DO NOT EDIT.
+-// Source: old.go
++// Source: new.go
+ 
+diff --git a/main.go b/main.go
+index abc123..def456 100644
+--- a/main.go
++++ b/main.go
+@@ -10,3 +10,4 @@
+ // Code generated by hand. DO NOT EDIT.
++code
+`
+	var headers []*regexp.Regexp
+	for _, p := range DefaultGeneratedHeaderPatterns() {
+		headers = append(headers, regexp.MustCompile(p))
+	}
+	result := Filter(diff, Options{GeneratedHeaders: headers})
+
+	if !slices.Equal(result.ExcludedFiles, []string{"api.pb.go", "mock.go"}) {
+		t.Errorf("expected excluded [api.pb.go mock.go], got %v", result.ExcludedFiles)
+	}
+	if result.Reasons["api.pb.go"] != "generated header" {
+		t.Errorf("expected generated header reason, got %q", result.Reasons["api.pb.go"])
+	}
+	if !strings.Contains(result.Diff, "+code") {
+		t.Error("expected main.go to be kept when the header is not at the top of the file")
+	}
+}
+
+func TestFilter_GeneratedHeaderFarFromEdit(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		body := strings.Repeat("var x = 1\n", 50)
+		writeFile(t, repo, "api.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n\n"+body)
+		writeFile(t, repo, "main.go", "package main\n\n"+body)
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "initial")
+		writeFile(t, repo, "api.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n\n"+body+"var y = 2\n")
+		writeFile(t, repo, "main.go", "package main\n\n"+body+"var y = 2\n")
+		runGit(t, repo, "add", ".")
+
+		diff, err := StagedDiff(DiffOptions{})
+		if err != nil {
+			t.Fatalf("StagedDiff error: %v", err)
+		}
+		if strings.Contains(diff, "Code generated") {
+			t.Fatalf("expected the header outside the diff context:\n%s", diff)
+		}
+		heads, err := FileHeads(DiffFiles(diff), false, GeneratedHeaderLines)
+		if err != nil {
+			t.Fatalf("FileHeads error: %v", err)
+		}
+		headers := []*regexp.Regexp{regexp.MustCompile(DefaultGeneratedHeaderPatterns()[0])}
+		result := Filter(diff, Options{GeneratedHeaders: headers, Heads: heads})
+		if !slices.Equal(result.ExcludedFiles, []string{"api.pb.go"}) {
+			t.Errorf("expected excluded [api.pb.go], got %v", result.ExcludedFiles)
+		}
+	})
+}
+
+func TestFilter_GeneratedHeaderFullAttributeWins(t *testing.T) {
+	diff := `diff --git a/api.pb.go b/api.pb.go
+--- a/api.pb.go
++++ b/api.pb.go
+@@ -1,1 +1,2 @@
++// Code generated by protoc-gen-go. DO NOT EDIT.
+`
+	result := Filter(diff, Options{
+		GeneratedHeaders: []*regexp.Regexp{regexp.MustCompile(DefaultGeneratedHeaderPatterns()[0])},
+		Attributes:       map[string]Attributes{"api.pb.go": {Mode: AttrModeFull}},
+	})
+	if len(result.ExcludedFiles) != 0 {
+		t.Errorf("expected no excluded files, got %v", result.ExcludedFiles)
+	}
+}
+
+func TestDefaultGeneratedHeaderPatterns(t *testing.T) {
+	headers := []string{
+		"// Code generated by protoc-gen-go. DO NOT EDIT.",
+		"// Code generated by sqlc. DO NOT EDIT.",
+		"// Code generated by MockGen. DO NOT EDIT.",
+		`// Code generated by "stringer -type=Kind"; DO NOT EDIT.`,
+		"# Generated by the protocol buffer compiler.  DO NOT EDIT!",
+		"// @generated",
+	}
+	for _, h := range headers {
+		matched := false
+		for _, p := range DefaultGeneratedHeaderPatterns() {
+			if regexp.MustCompile(p).MatchString(h) {
+				matched = true
+			}
+		}
+		if !matched {
+			t.Errorf("expected %q to match a default generated header pattern", h)
+		}
+	}
+	if regexp.MustCompile(DefaultGeneratedHeaderPatterns()[0]).MatchString("// Code review notes") {
+		t.Error("unexpected match for ordinary comment")
+	}
+}
+
+func TestFilter_TruncateFileDropsLines(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("diff --git a/large.go b/large.go\n--- a/large.go\n+++ b/large.go\n@@ -1,1 +1,50 @@\n")
+	for i := range 50 {
+		fmt.Fprintf(&sb, "+line %d\n", i)
+	}
+	result := Filter(sb.String(), Options{MaxFileLines: 10})
+	if !strings.Contains(result.Diff, "+line 9\n") {
+		t.Error("expected first 10 lines to be kept")
+	}
+	if strings.Contains(result.Diff, "+line 10\n") {
+		t.Errorf("expected lines beyond the limit to be dropped, got %s", result.Diff)
+	}
+	if !strings.Contains(result.Diff, "+++ b/large.go\n") {
+		t.Error("expected file header to be kept")
+	}
+}
+
+func TestFilter_TruncateFunctionContext(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("diff --git a/f.go b/f.go\n--- a/f.go\n+++ b/f.go\n@@ -1,60 +1,61 @@ func big()\n")
+	for i := range 30 {
+		fmt.Fprintf(&sb, " context %d\n", i)
+	}
+	sb.WriteString("+changed\n")
+	for i := 30; i < 60; i++ {
+		fmt.Fprintf(&sb, " context %d\n", i)
+	}
+	diff := sb.String()
+
+	// Without function context, unchanged lines count toward the limit.
+	plain := Filter(diff, Options{MaxFileLines: 20})
+	if !plain.Truncated || strings.Contains(plain.Diff, "+changed") {
+		t.Fatalf("expected plain truncation to drop the change, got %s", plain.Diff)
+	}
+
+	// With function context, only changed lines are budgeted.
+	fc := Filter(diff, Options{MaxFileLines: 20, FunctionContext: true})
+	if fc.Truncated {
+		t.Fatalf("expected no truncation with function context, got %s", fc.Diff)
+	}
+
+	// Unchanged lines are still capped at functionContextScale * MaxFileLines.
+	capped := Filter(diff, Options{MaxFileLines: 5, FunctionContext: true})
+	if !capped.Truncated {
+		t.Fatal("expected unchanged lines beyond the cap to truncate the file")
+	}
+	if !strings.Contains(capped.Diff, " context 19\n") || strings.Contains(capped.Diff, " context 20\n") {
+		t.Fatalf("expected 20 unchanged lines to be kept, got %s", capped.Diff)
+	}
+	if !strings.Contains(capped.Diff, "showing 0 of 1 changed lines") {
+		t.Fatalf("expected changed-line marker, got %s", capped.Diff)
+	}
+}
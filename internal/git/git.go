@@ -2,14 +2,50 @@ package git
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 )
 
-func StagedDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--staged")
+// DiffOptions controls how StagedDiff and LastCommitDiff generate diffs.
+type DiffOptions struct {
+	FunctionContext   bool   // Show whole functions as context (--function-context)
+	ContextLines      int    // Lines of context (-U); 0 uses git's default
+	Algorithm         string // Diff algorithm, e.g. "histogram"
+	FindRenames       bool   // Detect renames (-M)
+	FindCopies        bool   // Detect copies (-C)
+	IgnoreSpaceChange bool   // Ignore changes in amount of whitespace
+}
+
+func (o DiffOptions) args() []string {
+	var args []string
+	if o.FunctionContext {
+		args = append(args, "--function-context")
+	}
+	if o.ContextLines > 0 {
+		args = append(args, fmt.Sprintf("--unified=%d", o.ContextLines))
+	}
+	if o.Algorithm != "" {
+		args = append(args, "--diff-algorithm="+o.Algorithm)
+	}
+	if o.FindRenames {
+		args = append(args, "-M")
+	}
+	if o.FindCopies {
+		args = append(args, "-C")
+	}
+	if o.IgnoreSpaceChange {
+		args = append(args, "--ignore-space-change")
+	}
+	return args
+}
+
+func StagedDiff(opts DiffOptions) (string, error) {
+	args := append([]string{"diff", "--staged"}, opts.args()...)
+	cmd := exec.Command("git", args...)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -20,8 +56,9 @@ func StagedDiff() (string, error) {
 	return stdout.String(), nil
 }
 
-func LastCommitDiff() (string, error) {
-	cmd := exec.Command("git", "show", "HEAD", "--format=", "--no-color")
+func LastCommitDiff(opts DiffOptions) (string, error) {
+	args := append([]string{"show", "HEAD", "--format=", "--no-color"}, opts.args()...)
+	cmd := exec.Command("git", args...)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -95,48 +132,101 @@ func HasHeadCommit() (bool, error) {
 	return true, nil
 }
 
-func CommitWithMessage(message string, amend, edit, diff bool) error {
-	if edit {
-		return commitWithEdit(message, amend, diff)
-	}
-	args := []string{"commit", "-F", "-"}
-	if amend {
-		args = append(args, "--amend")
-	}
-	cmd := exec.Command("git", args...)
-	cmd.Stdin = strings.NewReader(message)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// CurrentBranch returns the short name of the checked-out branch, or "" when
+// HEAD is detached.
+func CurrentBranch() (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git commit failed: %v", err)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("git symbolic-ref failed: %v: %s", err, strings.TrimSpace(stderr.String()))
 	}
-	return nil
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Cleanup modes accepted by git commit --cleanup.
+var CleanupModes = []string{"strip", "whitespace", "verbatim", "scissors", "default"}
+
+// CommitOptions controls the git commit invocation made by
+// CommitWithMessage.
+type CommitOptions struct {
+	Amend      bool   // Amend the previous commit (--amend)
+	Edit       bool   // Open the message in the editor first
+	Verbose    bool   // Show the diff in the editor; needs Edit
+	Sign       bool   // GPG/SSH-sign the commit (-S)
+	SignKey    string // Key to sign with (-S<keyid>); implies Sign
+	NoVerify   bool   // Skip the pre-commit and commit-msg hooks (--no-verify)
+	Author     string // Override the author (--author)
+	Date       string // Override the author date (--date)
+	AllowEmpty bool   // Allow a commit without changes (--allow-empty)
+	Cleanup    string // Message cleanup mode (--cleanup), one of CleanupModes
+
+	// Comment is shown below the message in the editor, as comments that
+	// are removed again. CommentChar overrides core.commentChar for the
+	// commit so that the same character is stripped as was written.
+	Comment     string
+	CommentChar string
 }
 
-func commitWithEdit(message string, amend, diff bool) error {
-	f, err := os.CreateTemp("", "git-ai-commit-*.txt")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %v", err)
+// Validate returns an error if the options cannot be passed to git commit.
+func (o CommitOptions) Validate() error {
+	if o.Cleanup != "" && !slices.Contains(CleanupModes, o.Cleanup) {
+		return fmt.Errorf("invalid cleanup mode %q: must be one of %s", o.Cleanup, strings.Join(CleanupModes, ", "))
 	}
-	defer os.Remove(f.Name())
+	return nil
+}
 
-	if _, err := f.WriteString(message); err != nil {
-		f.Close()
-		return fmt.Errorf("failed to write temp file: %v", err)
+// args returns the git commit arguments reading the message from file, or
+// from stdin when file is "-".
+func (o CommitOptions) args(file string) []string {
+	var args []string
+	if o.CommentChar != "" {
+		args = append(args, "-c", "core.commentChar="+o.CommentChar)
+	}
+	args = append(args, "commit", "-F", file)
+	if o.Amend {
+		args = append(args, "--amend")
+	}
+	if o.SignKey != "" {
+		args = append(args, "-S"+o.SignKey)
+	} else if o.Sign {
+		args = append(args, "-S")
+	}
+	if o.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if o.Author != "" {
+		args = append(args, "--author="+o.Author)
+	}
+	if o.Date != "" {
+		args = append(args, "--date="+o.Date)
 	}
-	if err := f.Close(); err != nil {
-		return fmt.Errorf("failed to close temp file: %v", err)
+	if o.AllowEmpty {
+		args = append(args, "--allow-empty")
 	}
+	if o.Cleanup != "" {
+		args = append(args, "--cleanup="+o.Cleanup)
+	}
+	return args
+}
 
-	args := []string{"commit", "--edit", "-F", f.Name()}
-	if amend {
-		args = append(args, "--amend")
+// CommitWithMessage commits with message. With opts.Edit the message is
+// opened in the editor first, and revise, if not nil, regenerates it from
+// directives the user leaves there.
+func CommitWithMessage(message string, opts CommitOptions, revise Reviser) error {
+	if err := opts.Validate(); err != nil {
+		return err
 	}
-	if diff {
-		args = append(args, "--verbose")
+	if opts.Edit {
+		return editAndCommit(message, opts, revise)
 	}
-	cmd := exec.Command("git", args...)
-	cmd.Stdin = os.Stdin
+	cmd := exec.Command("git", opts.args("-")...)
+	cmd.Stdin = strings.NewReader(message)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -3,13 +3,15 @@ package git
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
 func TestStagedDiffEmpty(t *testing.T) {
 	repo := setupRepo(t)
 	withRepo(t, repo, func() {
-		diff, err := StagedDiff()
+		diff, err := StagedDiff(DiffOptions{})
 		if err != nil {
 			t.Fatalf("StagedDiff error: %v", err)
 		}
@@ -26,7 +28,7 @@ func TestLastCommitDiff(t *testing.T) {
 		runGit(t, repo, "add", "file.txt")
 		runGit(t, repo, "commit", "-m", "initial")
 
-		diff, err := LastCommitDiff()
+		diff, err := LastCommitDiff(DiffOptions{})
 		if err != nil {
 			t.Fatalf("LastCommitDiff error: %v", err)
 		}
@@ -35,6 +37,135 @@ func TestLastCommitDiff(t *testing.T) {
 		}
 	})
 }
+
+func TestDiffOptionsArgs(t *testing.T) {
+	opts := DiffOptions{
+		FunctionContext:   true,
+		ContextLines:      5,
+		Algorithm:         "histogram",
+		FindRenames:       true,
+		FindCopies:        true,
+		IgnoreSpaceChange: true,
+	}
+	got := strings.Join(opts.args(), " ")
+	want := "--function-context --unified=5 --diff-algorithm=histogram -M -C --ignore-space-change"
+	if got != want {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+	if len(DiffOptions{}.args()) != 0 {
+		t.Fatalf("expected no args for zero options")
+	}
+}
+
+func TestCommitOptionsArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CommitOptions
+		file string
+		want []string
+	}{
+		{"stdin", CommitOptions{}, "-", []string{"commit", "-F", "-"}},
+		{"amend", CommitOptions{Amend: true, Verbose: true}, "-", []string{"commit", "-F", "-", "--amend"}},
+		{"edited", CommitOptions{Amend: true, Edit: true, Verbose: true}, "msg.txt", []string{"commit", "-F", "msg.txt", "--amend"}},
+		{"sign", CommitOptions{Sign: true}, "-", []string{"commit", "-F", "-", "-S"}},
+		{"comment char", CommitOptions{Edit: true, Comment: "note", CommentChar: ";"}, "msg.txt", []string{"-c", "core.commentChar=;", "commit", "-F", "msg.txt"}},
+		{"passthrough", CommitOptions{
+			Sign:       true,
+			SignKey:    "ABCD1234",
+			NoVerify:   true,
+			Author:     "Jane Doe <jane@example.com>",
+			Date:       "2024-01-02T03:04:05",
+			AllowEmpty: true,
+			Cleanup:    "scissors",
+		}, "-", []string{
+			"commit", "-F", "-", "-SABCD1234", "--no-verify", "--author=Jane Doe <jane@example.com>",
+			"--date=2024-01-02T03:04:05", "--allow-empty", "--cleanup=scissors",
+		}},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.args(tt.file); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: args = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if err := (CommitOptions{Cleanup: "tidy"}).Validate(); err == nil {
+		t.Error("expected error for invalid cleanup mode")
+	}
+}
+
+func TestCommitWithMessageAllowEmpty(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		if err := CommitWithMessage("Start project", CommitOptions{AllowEmpty: true, Author: "Jane Doe <jane@example.com>"}, nil); err != nil {
+			t.Fatalf("CommitWithMessage error: %v", err)
+		}
+		out, err := gitOutput("log", "-1", "--format=%an|%s")
+		if err != nil {
+			t.Fatalf("git log error: %v", err)
+		}
+		if strings.TrimSpace(out) != "Jane Doe|Start project" {
+			t.Fatalf("last commit = %q", out)
+		}
+	})
+}
+
+func TestStagedDiffFunctionContext(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "main.go", "package main\n\nfunc main() {\n\ta := 1\n\tb := 2\n\tc := 3\n\td := 4\n\te := 5\n\tprintln(a, b, c, d, e)\n}\n")
+		runGit(t, repo, "add", "main.go")
+		runGit(t, repo, "commit", "-m", "initial")
+		writeFile(t, repo, "main.go", "package main\n\nfunc main() {\n\ta := 1\n\tb := 2\n\tc := 3\n\td := 4\n\te := 5\n\tprintln(a, b, c, d, e, 6)\n}\n")
+		runGit(t, repo, "add", "main.go")
+
+		plain, err := StagedDiff(DiffOptions{})
+		if err != nil {
+			t.Fatalf("StagedDiff error: %v", err)
+		}
+		if strings.Contains(plain, "\n func main() {\n") {
+			t.Fatalf("expected default context to omit the function header line:\n%s", plain)
+		}
+		fc, err := StagedDiff(DiffOptions{FunctionContext: true})
+		if err != nil {
+			t.Fatalf("StagedDiff error: %v", err)
+		}
+		if !strings.Contains(fc, "\n func main() {\n") {
+			t.Fatalf("expected function context to include the whole function:\n%s", fc)
+		}
+	})
+}
+
+func TestCheckAttr(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, ".gitattributes", "*.pb.go linguist-generated\n*.bin -diff\ndocs/* ai-commit=summary\n")
+		if err := os.MkdirAll(filepath.Join(repo, "docs"), 0o755); err != nil {
+			t.Fatalf("mkdir docs: %v", err)
+		}
+		writeFile(t, repo, "api.pb.go", "package api")
+		writeFile(t, repo, "data.bin", "data")
+		writeFile(t, repo, "docs/guide.md", "guide")
+		writeFile(t, repo, "main.go", "package main")
+		runGit(t, repo, "add", ".")
+
+		attrs, err := CheckAttr([]string{"api.pb.go", "data.bin", "docs/guide.md", "main.go"})
+		if err != nil {
+			t.Fatalf("CheckAttr error: %v", err)
+		}
+		if !attrs["api.pb.go"].Generated {
+			t.Fatalf("expected api.pb.go to be generated, got %+v", attrs["api.pb.go"])
+		}
+		if !attrs["data.bin"].NoDiff {
+			t.Fatalf("expected data.bin to be -diff, got %+v", attrs["data.bin"])
+		}
+		if attrs["docs/guide.md"].Mode != AttrModeSummary {
+			t.Fatalf("expected docs/guide.md mode summary, got %+v", attrs["docs/guide.md"])
+		}
+		if _, ok := attrs["main.go"]; ok {
+			t.Fatalf("expected no attributes for main.go, got %+v", attrs["main.go"])
+		}
+	})
+}
+
 func TestHasHeadCommitFalse(t *testing.T) {
 	repo := setupRepo(t)
 	withRepo(t, repo, func() {
@@ -48,6 +179,30 @@ func TestHasHeadCommitFalse(t *testing.T) {
 	})
 }
 
+func TestCurrentBranch(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		runGit(t, repo, "checkout", "-q", "-b", "feature/PROJ-12-export")
+		branch, err := CurrentBranch()
+		if err != nil {
+			t.Fatalf("CurrentBranch error: %v", err)
+		}
+		if branch != "feature/PROJ-12-export" {
+			t.Fatalf("CurrentBranch = %q, want feature/PROJ-12-export", branch)
+		}
+
+		runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+		runGit(t, repo, "checkout", "-q", "--detach")
+		branch, err = CurrentBranch()
+		if err != nil {
+			t.Fatalf("CurrentBranch error: %v", err)
+		}
+		if branch != "" {
+			t.Fatalf("CurrentBranch = %q on detached HEAD, want empty", branch)
+		}
+	})
+}
+
 func setupRepo(t *testing.T) string {
 	t.Helper()
 	base := t.TempDir()
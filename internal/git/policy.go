@@ -0,0 +1,100 @@
+package git
+
+import (
+	"slices"
+	"strings"
+)
+
+// PolicyMatch records a diff path matched by a never-send pattern.
+type PolicyMatch struct {
+	Path    string
+	Pattern string
+}
+
+// StripMatching removes every file section whose old or new path matches one
+// of the patterns and returns the remaining diff together with the matches.
+// Unlike Filter exclusions, stripped files leave no trace in the returned
+// diff.
+func StripMatching(diff string, patterns []string) (string, []PolicyMatch) {
+	if len(patterns) == 0 || strings.TrimSpace(diff) == "" {
+		return diff, nil
+	}
+	var out strings.Builder
+	var matches []PolicyMatch
+	for _, section := range splitSections(diff) {
+		skip := false
+		for _, path := range sectionPaths(section) {
+			if pattern, ok := firstMatchingPattern(path, patterns); ok {
+				matches = append(matches, PolicyMatch{Path: path, Pattern: pattern})
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			out.WriteString(section)
+		}
+	}
+	return out.String(), matches
+}
+
+// splitSections splits a diff into file sections, each starting at its
+// "diff --git" line. Text before the first section is returned as a section
+// of its own.
+func splitSections(diff string) []string {
+	var sections []string
+	start := 0
+	for i := 0; i < len(diff); {
+		next := strings.IndexByte(diff[i:], '\n')
+		if strings.HasPrefix(diff[i:], "diff --git ") && i > start {
+			sections = append(sections, diff[start:i])
+			start = i
+		}
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return append(sections, diff[start:])
+}
+
+// sectionPaths returns the distinct old and new paths of a file section.
+// They are read from the "rename from"/"rename to" (or "copy") lines when
+// present, which name each path on its own, and otherwise from the
+// "diff --git a/old b/new" header. Paths git quoted are unquoted.
+func sectionPaths(section string) []string {
+	lines := strings.Split(section, "\n")
+	if !strings.HasPrefix(lines[0], "diff --git ") {
+		return nil
+	}
+	var paths []string
+	add := func(p string) {
+		if p != "" && !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "Binary files ") {
+			break
+		}
+		for _, prefix := range []string{"rename from ", "rename to ", "copy from ", "copy to "} {
+			if p, ok := strings.CutPrefix(line, prefix); ok {
+				add(unquotePath(p))
+			}
+		}
+	}
+	if len(paths) == 0 {
+		oldPath, newPath := headerPaths(lines[0])
+		add(oldPath)
+		add(newPath)
+	}
+	return paths
+}
+
+func firstMatchingPattern(filePath string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if matchPattern(filePath, pattern) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const policyTestDiff = `diff --git a/main.go b/main.go
+index abc123..def456 100644
+--- a/main.go
++++ b/main.go
+@@ -1,1 +1,2 @@
++code
+diff --git a/secrets/prod.pem b/secrets/prod.pem
+new file mode 100644
+--- /dev/null
++++ b/secrets/prod.pem
+@@ -0,0 +1,1 @@
++key material
+diff --git a/fixtures/customers.csv b/testdata/customers.csv
+similarity index 100%
+rename from fixtures/customers.csv
+rename to testdata/customers.csv
+`
+
+func TestStripMatching(t *testing.T) {
+	got, matches := StripMatching(policyTestDiff, []string{"secrets/**", "fixtures/*.csv"})
+
+	want := []PolicyMatch{
+		{Path: "secrets/prod.pem", Pattern: "secrets/**"},
+		{Path: "fixtures/customers.csv", Pattern: "fixtures/*.csv"},
+	}
+	if !slices.Equal(matches, want) {
+		t.Fatalf("matches = %+v, want %+v", matches, want)
+	}
+	if strings.Contains(got, "prod.pem") || strings.Contains(got, "customers.csv") {
+		t.Fatalf("protected file names leaked into diff: %q", got)
+	}
+	if !strings.Contains(got, "+code") {
+		t.Fatalf("expected main.go to be kept: %q", got)
+	}
+}
+
+func TestStripMatchingNoPatterns(t *testing.T) {
+	got, matches := StripMatching(policyTestDiff, nil)
+	if got != policyTestDiff || len(matches) != 0 {
+		t.Fatalf("expected diff unchanged, got %q (%+v)", got, matches)
+	}
+}
+
+func TestSectionPaths(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"diff --git a/file.go b/file.go", []string{"file.go"}},
+		{"diff --git a/old/name.go b/new/name.go", []string{"old/name.go", "new/name.go"}},
+		{`diff --git "a/secrets/\320\272\320\273\321\216\321\207.pem" "b/secrets/\320\272\320\273\321\216\321\207.pem"`, []string{"secrets/ключ.pem"}},
+		{"diff --git a/my notes/b/x.txt b/my notes/b/x.txt", []string{"my notes/b/x.txt"}},
+		{"diff --git a/old name.txt b/new name.txt\nsimilarity index 100%\nrename from old name.txt\nrename to new name.txt\n", []string{"old name.txt", "new name.txt"}},
+		{"diff --git a/plain.txt \"b/caf\\303\\251.txt\"\nsimilarity index 100%\nrename from plain.txt\nrename to \"caf\\303\\251.txt\"\n", []string{"plain.txt", "café.txt"}},
+	}
+	for _, tt := range tests {
+		if got := sectionPaths(tt.line); !slices.Equal(got, tt.want) {
+			t.Errorf("sectionPaths(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestStripMatchingQuotedPaths(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		if err := os.MkdirAll(filepath.Join(repo, "secrets"), 0o755); err != nil {
+			t.Fatalf("mkdir secrets: %v", err)
+		}
+		writeFile(t, repo, "secrets/ключ.pem", "quoted key material")
+		writeFile(t, repo, "my secret.txt", "spaced secret")
+		writeFile(t, repo, "main.go", "package main")
+		runGit(t, repo, "add", ".")
+
+		diff, err := StagedDiff(DiffOptions{})
+		if err != nil {
+			t.Fatalf("StagedDiff error: %v", err)
+		}
+		got, matches := StripMatching(diff, []string{"*.pem", "my secret.txt"})
+		want := []PolicyMatch{
+			{Path: "my secret.txt", Pattern: "my secret.txt"},
+			{Path: "secrets/ключ.pem", Pattern: "*.pem"},
+		}
+		if !slices.Equal(matches, want) {
+			t.Fatalf("matches = %+v, want %+v", matches, want)
+		}
+		if strings.Contains(got, "key material") || strings.Contains(got, "spaced secret") {
+			t.Fatalf("protected contents leaked into diff: %q", got)
+		}
+		if !strings.Contains(got, "+package main") {
+			t.Fatalf("expected main.go to be kept: %q", got)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// autoCommentChars lists the characters core.commentChar=auto chooses
+// from, in git's order of preference.
+const autoCommentChars = "#;@!$%^&|:"
+
+// scissorsLine marks the end of the message for the scissors cleanup mode.
+const scissorsLine = "------------------------ >8 ------------------------"
+
+// CommitSettings holds the git configuration that shapes commit messages.
+type CommitSettings struct {
+	Template    string // contents of the commit.template file, or ""
+	CommentChar string // core.commentChar: a character, "auto" or "" for "#"
+	Cleanup     string // commit.cleanup, or "" for git's default
+}
+
+// ReadCommitSettings reads commit.template, core.commentChar and
+// commit.cleanup from git config.
+func ReadCommitSettings() (CommitSettings, error) {
+	var s CommitSettings
+	templatePath, err := configValue("--type=path", "commit.template")
+	if err != nil {
+		return s, err
+	}
+	if templatePath != "" {
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return s, fmt.Errorf("read commit.template: %w", err)
+		}
+		s.Template = strings.TrimSpace(string(data))
+	}
+	if s.CommentChar, err = configValue("core.commentChar"); err != nil {
+		return s, err
+	}
+	if s.Cleanup, err = configValue("commit.cleanup"); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// configValue returns the value of a git config key, or "" when it is unset.
+func configValue(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"config", "--get"}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("git config failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// CommentCharFor returns the comment character to use in an editor buffer
+// holding msg. Like git, "auto" picks the first candidate that starts no
+// line of msg.
+func (s CommitSettings) CommentCharFor(msg string) string {
+	switch s.CommentChar {
+	case "":
+		return "#"
+	case "auto":
+		for _, c := range autoCommentChars {
+			if !startsLine(msg, string(c)) {
+				return string(c)
+			}
+		}
+		return "#"
+	}
+	return s.CommentChar
+}
+
+func startsLine(msg, prefix string) bool {
+	for _, line := range strings.Split(msg, "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCommitSettings(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		s, err := ReadCommitSettings()
+		if err != nil {
+			t.Fatalf("ReadCommitSettings error: %v", err)
+		}
+		if s != (CommitSettings{}) {
+			t.Fatalf("ReadCommitSettings without config = %+v", s)
+		}
+
+		writeFile(t, repo, "template.txt", "Subject\n\n# Why:\n\nRefs:\n")
+		runGit(t, repo, "config", "commit.template", filepath.Join(repo, "template.txt"))
+		runGit(t, repo, "config", "core.commentChar", ";")
+		runGit(t, repo, "config", "commit.cleanup", "scissors")
+		s, err = ReadCommitSettings()
+		if err != nil {
+			t.Fatalf("ReadCommitSettings error: %v", err)
+		}
+		want := CommitSettings{Template: "Subject\n\n# Why:\n\nRefs:", CommentChar: ";", Cleanup: "scissors"}
+		if s != want {
+			t.Fatalf("ReadCommitSettings = %+v, want %+v", s, want)
+		}
+
+		runGit(t, repo, "config", "commit.template", filepath.Join(repo, "missing.txt"))
+		if _, err := ReadCommitSettings(); err == nil {
+			t.Fatal("expected error for missing template file")
+		}
+	})
+}
+
+func TestCommentCharFor(t *testing.T) {
+	tests := []struct {
+		setting, msg, want string
+	}{
+		{"", "Fix #12", "#"},
+		{";", "Fix it", ";"},
+		{"auto", "Fix it\n\n#12 was wrong", ";"},
+		{"auto", "Fix it\n\n#12\n;x\n@y", "!"},
+	}
+	for _, tt := range tests {
+		if got := (CommitSettings{CommentChar: tt.setting}).CommentCharFor(tt.msg); got != tt.want {
+			t.Errorf("CommentCharFor(%q, %q) = %q, want %q", tt.setting, tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestCommitWithEditStripsComment(t *testing.T) {
+	repo := setupRepo(t)
+	t.Setenv("GIT_EDITOR", "true")
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "file.txt", "hello")
+		runGit(t, repo, "add", "file.txt")
+		runGit(t, repo, "config", "core.commentChar", "auto")
+		opts := CommitOptions{Edit: true, Comment: "Generated by builtin", CommentChar: ";"}
+		if err := CommitWithMessage("Add file\n\n#1 is fixed", opts, nil); err != nil {
+			t.Fatalf("CommitWithMessage error: %v", err)
+		}
+		msg, err := CommitMessage("HEAD")
+		if err != nil {
+			t.Fatalf("CommitMessage error: %v", err)
+		}
+		if msg != "Add file\n\n#1 is fixed" {
+			t.Fatalf("commit message = %q", msg)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// FileStat describes one file changed by a commit or the staged changes.
+type FileStat struct {
+	Path    string // path after the change
+	OldPath string // source path for renames and copies
+	Status  string // added, modified, deleted, renamed, copied or type changed
+	Added   int    // lines added
+	Deleted int    // lines deleted
+	Binary  bool   // true when git reports no line counts
+}
+
+var statusNames = map[byte]string{
+	'A': "added",
+	'M': "modified",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+	'T': "type changed",
+	'U': "unmerged",
+}
+
+// StagedStat returns per-file statistics for the staged changes, with rename
+// detection enabled.
+func StagedStat() ([]FileStat, error) {
+	return diffStat([]string{"diff", "--staged"})
+}
+
+// LastCommitStat returns per-file statistics for the HEAD commit, with rename
+// detection enabled.
+func LastCommitStat() ([]FileStat, error) {
+	return diffStat([]string{"show", "HEAD", "--format="})
+}
+
+// WhitespaceOnly reports whether the staged changes, or the HEAD commit when
+// amend is set, change nothing but the amount of whitespace and blank lines.
+// Whitespace added or removed between words, as in a string literal, and
+// file mode changes count as real changes.
+func WhitespaceOnly(amend bool) (bool, error) {
+	base := []string{"diff", "--staged"}
+	if amend {
+		base = []string{"show", "HEAD", "--format="}
+	}
+	out, err := gitOutput(append(base, "--ignore-space-change", "--ignore-blank-lines", "--ignore-cr-at-eol", "--no-renames")...)
+	if err != nil {
+		return false, err
+	}
+	// With whitespace ignored, changed files still list a "diff --git"
+	// header but no hunks.
+	if strings.Contains(out, "\nold mode ") {
+		return false, nil
+	}
+	return !strings.Contains(out, "\n@@ "), nil
+}
+
+func diffStat(base []string) ([]FileStat, error) {
+	nameStatus, err := gitOutput(append(base, "--name-status", "-z", "-M")...)
+	if err != nil {
+		return nil, err
+	}
+	numStat, err := gitOutput(append(base, "--numstat", "-z", "-M")...)
+	if err != nil {
+		return nil, err
+	}
+	stats := parseNameStatus(nameStatus)
+	counts := parseNumStat(numStat)
+	for i := range stats {
+		if c, ok := counts[stats[i].Path]; ok {
+			stats[i].Added = c.Added
+			stats[i].Deleted = c.Deleted
+			stats[i].Binary = c.Binary
+		}
+	}
+	return stats, nil
+}
+
+// parseNameStatus parses "git diff --name-status -z" output.
+func parseNameStatus(out string) []FileStat {
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	var stats []FileStat
+	for i := 0; i < len(fields); i++ {
+		code := fields[i]
+		if code == "" {
+			continue
+		}
+		status, ok := statusNames[code[0]]
+		if !ok {
+			status = "modified"
+		}
+		if (code[0] == 'R' || code[0] == 'C') && i+2 < len(fields) {
+			stats = append(stats, FileStat{Path: fields[i+2], OldPath: fields[i+1], Status: status})
+			i += 2
+			continue
+		}
+		if i+1 < len(fields) {
+			stats = append(stats, FileStat{Path: fields[i+1], Status: status})
+			i++
+		}
+	}
+	return stats
+}
+
+// parseNumStat parses "git diff --numstat -z" output into counts keyed by the
+// path after the change.
+func parseNumStat(out string) map[string]FileStat {
+	counts := make(map[string]FileStat)
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		path := parts[2]
+		if path == "" && i+2 < len(fields) {
+			// Renames and copies: "added\tdeleted\t" NUL old NUL new
+			path = fields[i+2]
+			i += 2
+		}
+		var c FileStat
+		if parts[0] == "-" && parts[1] == "-" {
+			c.Binary = true
+		} else {
+			c.Added, _ = strconv.Atoi(parts[0])
+			c.Deleted, _ = strconv.Atoi(parts[1])
+		}
+		counts[path] = c
+	}
+	return counts
+}
+
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// FileAt returns the contents of path, relative to the repository root, at
+// rev. An empty rev reads the staged version. The boolean result
+// is false when the file does not exist at rev, including when rev itself
+// does not exist, as for HEAD^ of a root commit.
+func FileAt(rev, path string) ([]byte, bool, error) {
+	spec := rev + ":" + path
+	if err := exec.Command("git", "cat-file", "-e", spec).Run(); err != nil {
+		return nil, false, nil
+	}
+	out, err := gitOutput("cat-file", "blob", spec)
+	if err != nil {
+		return nil, false, err
+	}
+	return []byte(out), true, nil
+}
+
+// CommitMessage returns the full message of the commit rev.
+func CommitMessage(rev string) (string, error) {
+	out, err := gitOutput("log", "-1", "--format=%B", rev, "--")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Note returns the note attached to rev in the notes ref, such as
+// "review" or "refs/notes/review", or "" when there is none.
+func Note(ref, rev string) (string, error) {
+	cmd := exec.Command("git", "notes", "--ref="+ref, "show", rev)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("git notes failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Commit is a commit read from the history.
+type Commit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Message string // full message, trimmed
+}
+
+// RecentCommits returns up to limit non-merge commits reachable from rev,
+// newest first. When paths is not empty, only commits touching one of the
+// paths, relative to the repository root as in diff output, are returned.
+func RecentCommits(rev string, limit int, paths []string) ([]Commit, error) {
+	args := []string{"log", "--no-merges", "-z", "--format=%H%x1f%an%x1f%ae%x1f%B", "-n", strconv.Itoa(limit), rev, "--"}
+	for _, p := range paths {
+		// git log reads pathspecs relative to the working directory.
+		args = append(args, ":(top,literal)"+p)
+	}
+	out, err := gitOutput(args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x00") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Message: strings.TrimSpace(fields[3]),
+		})
+	}
+	return commits, nil
+}
@@ -0,0 +1,219 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseNameStatus(t *testing.T) {
+	out := "M\x00b.bin\x00R060\x00c.go\x00d.go\x00A\x00new.go\x00D\x00old.go\x00"
+	got := parseNameStatus(out)
+	want := []FileStat{
+		{Path: "b.bin", Status: "modified"},
+		{Path: "d.go", OldPath: "c.go", Status: "renamed"},
+		{Path: "new.go", Status: "added"},
+		{Path: "old.go", Status: "deleted"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("parseNameStatus = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseNumStat(t *testing.T) {
+	out := "-\t-\tb.bin\x001\t0\t\x00c.go\x00d.go\x0012\t8\tgo.sum\x00"
+	got := parseNumStat(out)
+	if !got["b.bin"].Binary {
+		t.Fatalf("expected b.bin to be binary: %+v", got["b.bin"])
+	}
+	if got["d.go"].Added != 1 || got["d.go"].Deleted != 0 {
+		t.Fatalf("unexpected rename counts: %+v", got["d.go"])
+	}
+	if got["go.sum"].Added != 12 || got["go.sum"].Deleted != 8 {
+		t.Fatalf("unexpected go.sum counts: %+v", got["go.sum"])
+	}
+}
+
+func TestStagedStat(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "old.txt", "one\ntwo\nthree\nfour\nfive\n")
+		writeFile(t, repo, "keep.txt", "a\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "initial")
+
+		runGit(t, repo, "mv", "old.txt", "new.txt")
+		writeFile(t, repo, "keep.txt", "a\nb\nc\n")
+		runGit(t, repo, "add", ".")
+
+		stats, err := StagedStat()
+		if err != nil {
+			t.Fatalf("StagedStat error: %v", err)
+		}
+		want := []FileStat{
+			{Path: "keep.txt", Status: "modified", Added: 2},
+			{Path: "new.txt", OldPath: "old.txt", Status: "renamed"},
+		}
+		if !slices.Equal(stats, want) {
+			t.Fatalf("StagedStat = %+v, want %+v", stats, want)
+		}
+
+		runGit(t, repo, "commit", "-m", "rename")
+		stats, err = LastCommitStat()
+		if err != nil {
+			t.Fatalf("LastCommitStat error: %v", err)
+		}
+		if !slices.Equal(stats, want) {
+			t.Fatalf("LastCommitStat = %+v, want %+v", stats, want)
+		}
+	})
+}
+
+func TestFileAt(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "a.txt", "committed\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "initial")
+		writeFile(t, repo, "a.txt", "staged\n")
+		runGit(t, repo, "add", ".")
+		writeFile(t, repo, "a.txt", "worktree\n")
+
+		got, ok, err := FileAt("HEAD", "a.txt")
+		if err != nil || !ok || string(got) != "committed\n" {
+			t.Fatalf("FileAt(HEAD) = %q, %v, %v", got, ok, err)
+		}
+		got, ok, err = FileAt("", "a.txt")
+		if err != nil || !ok || string(got) != "staged\n" {
+			t.Fatalf("FileAt(index) = %q, %v, %v", got, ok, err)
+		}
+		if _, ok, err := FileAt("HEAD", "missing.txt"); err != nil || ok {
+			t.Fatalf("FileAt(missing) = %v, %v, want not found", ok, err)
+		}
+		if _, ok, err := FileAt("HEAD^", "a.txt"); err != nil || ok {
+			t.Fatalf("FileAt(HEAD^) = %v, %v, want not found", ok, err)
+		}
+	})
+}
+
+func TestWhitespaceOnly(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "main.go", "package main\n\nfunc main() {\n    println()\n}\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "initial")
+
+		writeFile(t, repo, "main.go", "package main\n\n\nfunc main() {\n\tprintln()\n}\n")
+		runGit(t, repo, "add", ".")
+		ok, err := WhitespaceOnly(false)
+		if err != nil || !ok {
+			t.Fatalf("WhitespaceOnly = %v, %v, want true", ok, err)
+		}
+
+		writeFile(t, repo, "main.go", "package main\n\nfunc main() {\n\tprintln(1)\n}\n")
+		runGit(t, repo, "add", ".")
+		ok, err = WhitespaceOnly(false)
+		if err != nil || ok {
+			t.Fatalf("WhitespaceOnly = %v, %v, want false", ok, err)
+		}
+
+		writeFile(t, repo, "main.go", "package main\n\nfunc main() {\n\tprintln(\"ab\")\n}\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "string")
+		writeFile(t, repo, "main.go", "package main\n\nfunc main() {\n\tprintln(\"a b\")\n}\n")
+		runGit(t, repo, "add", ".")
+		ok, err = WhitespaceOnly(false)
+		if err != nil || ok {
+			t.Fatalf("WhitespaceOnly with a space added inside a string = %v, %v, want false", ok, err)
+		}
+		runGit(t, repo, "reset", "-q", "--hard")
+
+		writeFile(t, repo, "run.sh", "#!/bin/sh\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "script")
+		runGit(t, repo, "update-index", "--chmod=+x", "run.sh")
+		ok, err = WhitespaceOnly(false)
+		if err != nil || ok {
+			t.Fatalf("WhitespaceOnly with a mode change = %v, %v, want false", ok, err)
+		}
+
+		runGit(t, repo, "commit", "-m", "change")
+		ok, err = WhitespaceOnly(true)
+		if err != nil || ok {
+			t.Fatalf("WhitespaceOnly(amend) = %v, %v, want false", ok, err)
+		}
+	})
+}
+
+func TestRecentCommits(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		writeFile(t, repo, "a.txt", "a\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "Add a\n\nFirst body.")
+		writeFile(t, repo, "b.txt", "b\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "Add b")
+
+		commits, err := RecentCommits("HEAD", 10, nil)
+		if err != nil {
+			t.Fatalf("RecentCommits error: %v", err)
+		}
+		if len(commits) != 2 || commits[0].Message != "Add b" || commits[1].Message != "Add a\n\nFirst body." {
+			t.Fatalf("RecentCommits = %+v", commits)
+		}
+		if commits[0].Hash == "" || commits[0].Author == "" {
+			t.Fatalf("missing commit metadata: %+v", commits[0])
+		}
+
+		commits, err = RecentCommits("HEAD", 10, []string{"a.txt"})
+		if err != nil {
+			t.Fatalf("RecentCommits error: %v", err)
+		}
+		if len(commits) != 1 || commits[0].Message != "Add a\n\nFirst body." {
+			t.Fatalf("RecentCommits(paths) = %+v", commits)
+		}
+
+		if err := os.MkdirAll(filepath.Join(repo, "pkg", "sub"), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeFile(t, repo, "pkg/sub/f.go", "package sub\n")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "Add f")
+		withRepo(t, filepath.Join(repo, "pkg"), func() {
+			commits, err := RecentCommits("HEAD", 10, []string{"pkg/sub/f.go"})
+			if err != nil {
+				t.Fatalf("RecentCommits error: %v", err)
+			}
+			if len(commits) != 1 || commits[0].Message != "Add f" {
+				t.Fatalf("RecentCommits(paths) from a subdirectory = %+v", commits)
+			}
+		})
+	})
+}
+
+func TestNote(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+		note, err := Note("review", "HEAD")
+		if err != nil {
+			t.Fatalf("Note error: %v", err)
+		}
+		if note != "" {
+			t.Fatalf("Note without a note = %q", note)
+		}
+
+		runGit(t, repo, "notes", "--ref=review", "add", "-m", "Tighten the cache TTL", "HEAD")
+		for _, ref := range []string{"review", "refs/notes/review"} {
+			note, err := Note(ref, "HEAD")
+			if err != nil {
+				t.Fatalf("Note(%s) error: %v", ref, err)
+			}
+			if note != "Tighten the cache TTL" {
+				t.Fatalf("Note(%s) = %q", ref, note)
+			}
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// AddTrailers appends trailers, each formatted as "Key: value", to the
+// trailer block of msg using git interpret-trailers, so that formatting and
+// trailer.* settings follow git. A trailer already present with the same
+// value is not added again.
+func AddTrailers(msg string, trailers []string) (string, error) {
+	if len(trailers) == 0 {
+		return msg, nil
+	}
+	args := []string{"interpret-trailers", "--no-divider", "--if-exists", "addIfDifferent"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t)
+	}
+	out, err := interpretTrailers(msg, args)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+// ParseTrailers returns the trailers of msg as "Key: value" lines.
+func ParseTrailers(msg string) ([]string, error) {
+	out, err := interpretTrailers(msg, []string{"interpret-trailers", "--no-divider", "--parse"})
+	if err != nil {
+		return nil, err
+	}
+	var trailers []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			trailers = append(trailers, line)
+		}
+	}
+	return trailers, nil
+}
+
+func interpretTrailers(msg string, args []string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(msg + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git interpret-trailers failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// CommitterIdent returns the committer as "Name <email>", the form used in
+// Signed-off-by trailers.
+func CommitterIdent() (string, error) {
+	out, err := gitOutput("var", "GIT_COMMITTER_IDENT")
+	if err != nil {
+		return "", err
+	}
+	ident := strings.TrimSpace(out)
+	// Drop the trailing timestamp and time zone.
+	if end := strings.LastIndex(ident, ">"); end >= 0 {
+		ident = ident[:end+1]
+	}
+	return ident, nil
+}
+
+// Authors returns the distinct commit authors as "Name <email>", most
+// recent first. It returns nil when there are no commits.
+func Authors() ([]string, error) {
+	hasHead, err := HasHeadCommit()
+	if err != nil || !hasHead {
+		return nil, err
+	}
+	out, err := gitOutput("log", "--format=%an <%ae>")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var authors []string
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		authors = append(authors, line)
+	}
+	return authors, nil
+}
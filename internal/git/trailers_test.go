@@ -0,0 +1,60 @@
+package git
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAddTrailers(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		msg := "Add export\n\nExplain why.\n---\nNot a patch divider.\n\nRefs: PROJ-1"
+		got, err := AddTrailers(msg, []string{"Refs: PROJ-1", "Co-authored-by: Jane <jane@example.com>", "Signed-off-by: Test User <test@example.com>"})
+		if err != nil {
+			t.Fatalf("AddTrailers error: %v", err)
+		}
+		want := "Add export\n\nExplain why.\n---\nNot a patch divider.\n\nRefs: PROJ-1\nCo-authored-by: Jane <jane@example.com>\nSigned-off-by: Test User <test@example.com>"
+		if got != want {
+			t.Fatalf("AddTrailers = %q, want %q", got, want)
+		}
+
+		trailers, err := ParseTrailers(got)
+		if err != nil {
+			t.Fatalf("ParseTrailers error: %v", err)
+		}
+		if len(trailers) != 3 || trailers[0] != "Refs: PROJ-1" {
+			t.Fatalf("ParseTrailers = %q", trailers)
+		}
+		if trailers, _ := ParseTrailers("Add export\n\nJust a body."); trailers != nil {
+			t.Fatalf("ParseTrailers without trailers = %q", trailers)
+		}
+	})
+}
+
+func TestCommitterIdentAndAuthors(t *testing.T) {
+	repo := setupRepo(t)
+	withRepo(t, repo, func() {
+		ident, err := CommitterIdent()
+		if err != nil {
+			t.Fatalf("CommitterIdent error: %v", err)
+		}
+		if ident != "Test User <test@example.com>" {
+			t.Fatalf("CommitterIdent = %q", ident)
+		}
+
+		if authors, err := Authors(); err != nil || authors != nil {
+			t.Fatalf("Authors without commits = %v, %v", authors, err)
+		}
+		runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "one", "--author", "Jane Doe <jane@example.com>")
+		runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "two")
+		runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "three", "--author", "Jane Doe <jane@example.com>")
+		authors, err := Authors()
+		if err != nil {
+			t.Fatalf("Authors error: %v", err)
+		}
+		want := []string{"Jane Doe <jane@example.com>", "Test User <test@example.com>"}
+		if !slices.Equal(authors, want) {
+			t.Fatalf("Authors = %v, want %v", authors, want)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package glossary
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Replacement records a preferred term written in place of an avoided one.
+type Replacement struct {
+	Avoid string // the avoided term as it appeared in the message
+	Use   string // the term written instead
+}
+
+// Prefer replaces the avoided terms in msg, the keys of prefer, with their
+// preferred terms. Terms match case-insensitively as whole words, longest
+// first, and a capitalised match gets a capitalised replacement. The
+// replacements are returned in the order they were made.
+func Prefer(msg string, prefer map[string]string) (string, []Replacement) {
+	avoid := make([]string, 0, len(prefer))
+	for term := range prefer {
+		if strings.TrimSpace(term) != "" {
+			avoid = append(avoid, term)
+		}
+	}
+	slices.SortFunc(avoid, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), cmp.Compare(a, b))
+	})
+
+	var replaced []Replacement
+	for _, term := range avoid {
+		matches := findWord(msg, term)
+		for _, m := range matches {
+			found := msg[m[0]:m[1]]
+			replaced = append(replaced, Replacement{Avoid: found, Use: matchCase(found, prefer[term])})
+		}
+		// Replace from the end so earlier indexes stay valid.
+		for i := len(matches) - 1; i >= 0; i-- {
+			m := matches[i]
+			msg = msg[:m[0]] + matchCase(msg[m[0]:m[1]], prefer[term]) + msg[m[1]:]
+		}
+	}
+	return msg, replaced
+}
+
+// Forbidden returns the forbidden terms that appear in msg as whole words,
+// ignoring case, in the order of terms.
+func Forbidden(msg string, terms []string) []string {
+	var found []string
+	for _, term := range terms {
+		if strings.TrimSpace(term) != "" && len(findWord(msg, term)) > 0 {
+			found = append(found, term)
+		}
+	}
+	return found
+}
+
+// findWord returns the index pairs of the case-insensitive matches of term
+// in s that are not part of a longer word or of a name such as "e2e.txt",
+// "pkg/e2e" or "e2e-tests".
+func findWord(s, term string) [][]int {
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	var matches [][]int
+	for _, m := range re.FindAllStringIndex(s, -1) {
+		before, size := utf8.DecodeLastRuneInString(s[:m[0]])
+		if isWordRune(before) {
+			continue
+		}
+		if strings.ContainsRune(nameJoiners, before) {
+			if r, _ := utf8.DecodeLastRuneInString(s[:m[0]-size]); isWordRune(r) {
+				continue
+			}
+		}
+		after, size := utf8.DecodeRuneInString(s[m[1]:])
+		if isWordRune(after) {
+			continue
+		}
+		if strings.ContainsRune(nameJoiners, after) {
+			if r, _ := utf8.DecodeRuneInString(s[m[1]+size:]); isWordRune(r) {
+				continue
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// nameJoiners join words into file names, paths and identifiers.
+const nameJoiners = "./-"
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// matchCase capitalises use when found starts with an upper-case letter
+// and use with a lower-case one, as at the start of a subject.
+func matchCase(found, use string) string {
+	f, _ := utf8.DecodeRuneInString(found)
+	u, size := utf8.DecodeRuneInString(use)
+	if unicode.IsUpper(f) && unicode.IsLower(u) {
+		return string(unicode.ToUpper(u)) + use[size:]
+	}
+	return use
+}
@@ -0,0 +1,44 @@
+package glossary
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPrefer(t *testing.T) {
+	prefer := map[string]string{
+		"config file": "manifest",
+		"config":      "settings",
+		"github":      "GitHub",
+		"Acme cloud":  "AcmeCloud",
+		"    ":        "ignored",
+	}
+	tests := []struct {
+		msg, want string
+		replaced  []Replacement
+	}{
+		{"Config file parsing fails on BOM", "Manifest parsing fails on BOM", []Replacement{{"Config file", "Manifest"}}},
+		{"Read the config file and the config", "Read the manifest and the settings", []Replacement{{"config file", "manifest"}, {"config", "settings"}}},
+		{"Mirror to github and acme CLOUD", "Mirror to GitHub and AcmeCloud", []Replacement{{"acme CLOUD", "AcmeCloud"}, {"github", "GitHub"}}},
+		{"Rename configure_file and configs", "Rename configure_file and configs", nil},
+		{"Move config.go to pkg/config, not the config-loader", "Move config.go to pkg/config, not the config-loader", nil},
+		{"Fix the config. Also the github-hosted config.", "Fix the settings. Also the github-hosted settings.", []Replacement{{"config", "settings"}, {"config", "settings"}}},
+	}
+	for _, tt := range tests {
+		got, replaced := Prefer(tt.msg, prefer)
+		if got != tt.want {
+			t.Errorf("Prefer(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+		if !slices.Equal(replaced, tt.replaced) {
+			t.Errorf("Prefer(%q) replaced %v, want %v", tt.msg, replaced, tt.replaced)
+		}
+	}
+}
+
+func TestForbidden(t *testing.T) {
+	terms := []string{"simply", "Project X", "hack"}
+	got := Forbidden("Simply drop the project x flag\n\nNo hacks needed.", terms)
+	if want := []string{"simply", "Project X"}; !slices.Equal(got, want) {
+		t.Fatalf("Forbidden = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,371 @@
+package goapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Declaration kinds reported in Change.Kind.
+const (
+	KindFunc            = "func"
+	KindMethod          = "method"
+	KindType            = "type"
+	KindField           = "field"
+	KindInterfaceMethod = "interface method"
+	KindConst           = "const"
+	KindVar             = "var"
+)
+
+// Change actions reported in Change.Action.
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+)
+
+// Change describes one change to an exported declaration in a Go file.
+type Change struct {
+	File         string
+	Package      string
+	Kind         string // func, method, type, field, interface method, const or var
+	Name         string // qualified within the package, e.g. "Config.Load"
+	Action       string // added, removed or modified
+	Signature    string // declaration after the change (before it, when removed)
+	OldSignature string // declaration before the change, when modified
+}
+
+// SignatureChanged reports whether a modified declaration changed its
+// signature rather than only its body.
+func (c Change) SignatureChanged() bool {
+	return c.Action == Modified && c.OldSignature != c.Signature
+}
+
+// MergeMoves pairs declarations removed from one file and added to another
+// file of the same package, as when code moves between files, and replaces
+// each pair with a single modified change on the new file.
+func MergeMoves(changes []Change) []Change {
+	key := func(c Change) string {
+		return path.Dir(c.File) + "\x00" + c.Package + "\x00" + c.Kind + "\x00" + c.Name
+	}
+	removed := make(map[string]int)
+	for i, c := range changes {
+		if c.Action == Removed {
+			removed[key(c)] = i
+		}
+	}
+	moved := make(map[int]bool)
+	var out []Change
+	for _, c := range changes {
+		if c.Action == Added {
+			if i, ok := removed[key(c)]; ok && !moved[i] && changes[i].File != c.File {
+				moved[i] = true
+				c.Action = Modified
+				c.OldSignature = changes[i].Signature
+			}
+		}
+		out = append(out, c)
+	}
+	merged := out[:0]
+	for i, c := range out {
+		if !moved[i] {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
+
+// Breaking reports whether the change can break code that imports the
+// package: removing a declaration, changing its signature, or adding a
+// method to an interface that callers may implement.
+func (c Change) Breaking() bool {
+	switch c.Action {
+	case Removed:
+		return true
+	case Added:
+		return c.Kind == KindInterfaceMethod
+	}
+	return c.SignatureChanged()
+}
+
+// Describe returns a one-line description of a breaking change.
+func (c Change) Describe() string {
+	name := c.Package + "." + c.Name
+	switch {
+	case c.Action == Removed:
+		return fmt.Sprintf("removed exported %s %s", c.Kind, name)
+	case c.Action == Added && c.Kind == KindInterfaceMethod:
+		return fmt.Sprintf("added %s %s; existing implementations no longer satisfy the interface", c.Kind, name)
+	case c.SignatureChanged():
+		return fmt.Sprintf("changed %s %s from %q to %q", c.Kind, name, c.OldSignature, c.Signature)
+	}
+	return fmt.Sprintf("%s %s %s", c.Action, c.Kind, name)
+}
+
+// Importable reports whether a package declared as pkg in the file at path
+// can be imported by other modules. Main packages and packages below an
+// internal or testdata directory cannot.
+func Importable(path, pkg string) bool {
+	if pkg == "main" {
+		return false
+	}
+	dirs := strings.Split(path, "/")
+	for _, dir := range dirs[:len(dirs)-1] {
+		if dir == "internal" || dir == "testdata" {
+			return false
+		}
+	}
+	return true
+}
+
+// ModulePath returns the module path declared in a go.mod file, or "" if
+// there is none.
+func ModulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`+"`")
+		}
+	}
+	return ""
+}
+
+type decl struct {
+	kind      string
+	name      string
+	signature string
+	body      string
+}
+
+// Compare returns the changes to exported declarations between two versions
+// of a Go source file. oldSrc is nil for added files and newSrc is nil for
+// deleted files.
+func Compare(file string, oldSrc, newSrc []byte) ([]Change, error) {
+	oldDecls, oldPkg, err := parseDecls(file, oldSrc)
+	if err != nil {
+		return nil, err
+	}
+	newDecls, newPkg, err := parseDecls(file, newSrc)
+	if err != nil {
+		return nil, err
+	}
+	pkg := newPkg
+	if pkg == "" {
+		pkg = oldPkg
+	}
+
+	keys := make(map[string]bool)
+	for k := range oldDecls {
+		keys[k] = true
+	}
+	for k := range newDecls {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, k := range sorted {
+		o, inOld := oldDecls[k]
+		n, inNew := newDecls[k]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{File: file, Package: pkg, Kind: n.kind, Name: n.name, Action: Added, Signature: n.signature})
+		case !inNew:
+			changes = append(changes, Change{File: file, Package: pkg, Kind: o.kind, Name: o.name, Action: Removed, Signature: o.signature})
+		case o.signature != n.signature || o.body != n.body:
+			changes = append(changes, Change{File: file, Package: pkg, Kind: n.kind, Name: n.name, Action: Modified, Signature: n.signature, OldSignature: o.signature})
+		}
+	}
+	return changes, nil
+}
+
+func parseDecls(file string, src []byte) (map[string]decl, string, error) {
+	decls := make(map[string]decl)
+	if src == nil {
+		return decls, "", nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, "", fmt.Errorf("parse %s: %w", file, err)
+	}
+	add := func(d decl) {
+		decls[d.kind+" "+d.name] = d
+	}
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			kind, name := KindFunc, d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				recv := receiverName(d.Recv.List[0].Type)
+				if !ast.IsExported(recv) {
+					continue
+				}
+				kind, name = KindMethod, recv+"."+d.Name.Name
+			}
+			sig := &ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type}
+			add(decl{kind: kind, name: name, signature: render(fset, sig), body: render(fset, d.Body)})
+		case *ast.GenDecl:
+			switch d.Tok {
+			case token.TYPE:
+				for _, spec := range d.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if !ts.Name.IsExported() {
+						continue
+					}
+					for _, m := range typeDecls(fset, ts) {
+						add(m)
+					}
+				}
+			case token.CONST, token.VAR:
+				for _, spec := range d.Specs {
+					for _, v := range valueDecls(fset, d.Tok, spec.(*ast.ValueSpec)) {
+						add(v)
+					}
+				}
+			}
+		}
+	}
+	return decls, f.Name.Name, nil
+}
+
+// typeDecls returns the declaration for a type and, for structs and
+// interfaces, for each of its exported fields or methods.
+func typeDecls(fset *token.FileSet, ts *ast.TypeSpec) []decl {
+	name := ts.Name.Name
+	var decls []decl
+	switch t := ts.Type.(type) {
+	case *ast.StructType:
+		decls = append(decls, decl{kind: KindType, name: name, signature: "type " + name + typeParams(fset, ts) + " struct"})
+		for _, field := range t.Fields.List {
+			typ := render(fset, field.Type)
+			if len(field.Names) == 0 {
+				embedded := receiverName(field.Type)
+				if ast.IsExported(embedded) {
+					decls = append(decls, decl{kind: KindField, name: name + "." + embedded, signature: typ})
+				}
+				continue
+			}
+			for _, n := range field.Names {
+				if n.IsExported() {
+					decls = append(decls, decl{kind: KindField, name: name + "." + n.Name, signature: n.Name + " " + typ})
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		decls = append(decls, decl{kind: KindType, name: name, signature: "type " + name + typeParams(fset, ts) + " interface"})
+		for _, m := range t.Methods.List {
+			if len(m.Names) == 0 {
+				// Embedded interface or type constraint.
+				embedded := render(fset, m.Type)
+				decls = append(decls, decl{kind: KindInterfaceMethod, name: name + "." + embedded, signature: embedded})
+				continue
+			}
+			for _, n := range m.Names {
+				if n.IsExported() {
+					sig := strings.TrimPrefix(render(fset, m.Type), "func")
+					decls = append(decls, decl{kind: KindInterfaceMethod, name: name + "." + n.Name, signature: n.Name + sig})
+				}
+			}
+		}
+	default:
+		assign := " "
+		if ts.Assign.IsValid() {
+			assign = " = "
+		}
+		decls = append(decls, decl{kind: KindType, name: name, signature: "type " + name + typeParams(fset, ts) + assign + render(fset, ts.Type)})
+	}
+	return decls
+}
+
+// valueDecls returns a declaration for each exported name of a const or var
+// spec. The signature carries the type when the spec spells it out; the
+// value is compared as the body, so changing only a value is not breaking.
+func valueDecls(fset *token.FileSet, tok token.Token, vs *ast.ValueSpec) []decl {
+	kind := KindVar
+	if tok == token.CONST {
+		kind = KindConst
+	}
+	var decls []decl
+	for i, n := range vs.Names {
+		if !n.IsExported() {
+			continue
+		}
+		sig := kind + " " + n.Name
+		if vs.Type != nil {
+			sig += " " + render(fset, vs.Type)
+		}
+		var value string
+		if i < len(vs.Values) {
+			value = render(fset, vs.Values[i])
+		}
+		decls = append(decls, decl{kind: kind, name: n.Name, signature: sig, body: value})
+	}
+	return decls
+}
+
+func typeParams(fset *token.FileSet, ts *ast.TypeSpec) string {
+	if ts.TypeParams == nil || len(ts.TypeParams.List) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, field := range ts.TypeParams.List {
+		var names []string
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+		parts = append(parts, strings.Join(names, ", ")+" "+render(fset, field.Type))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// receiverName returns the base type name of a receiver or embedded field
+// expression, stripping pointers, packages and type arguments.
+func receiverName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			return e.Sel.Name
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func render(fset *token.FileSet, node any) string {
+	if node == nil {
+		return ""
+	}
+	if body, ok := node.(*ast.BlockStmt); ok && body == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
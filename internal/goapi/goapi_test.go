@@ -0,0 +1,265 @@
+package goapi
+
+import (
+	"slices"
+	"testing"
+)
+
+const oldSource = `package app
+
+type Options struct {
+	Amend bool
+	Edit  bool
+	debug bool
+}
+
+type Engine interface {
+	Generate(prompt string) (string, error)
+	Name() string
+}
+
+type Mode int
+
+func Run(name string) error { return nil }
+
+func (o *Options) Validate() error { return nil }
+
+func helper() {}
+
+type internalType struct{}
+
+func (internalType) Exported() {}
+`
+
+const newSource = `package app
+
+type Options struct {
+	Amend   bool
+	Timeout int
+	debug   bool
+}
+
+type Engine interface {
+	Generate(prompt string) (string, error)
+	Close() error
+}
+
+type Mode string
+
+func Run(opts Options) error { return nil }
+
+func (o *Options) Validate() error { return validate(o) }
+
+func Load() {}
+
+func helper() { println() }
+
+type internalType struct{}
+
+func (internalType) Exported() { println() }
+`
+
+func TestCompare(t *testing.T) {
+	changes, err := Compare("app/app.go", []byte(oldSource), []byte(newSource))
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	want := []Change{
+		{Kind: KindField, Name: "Options.Edit", Action: Removed, Signature: "Edit bool"},
+		{Kind: KindField, Name: "Options.Timeout", Action: Added, Signature: "Timeout int"},
+		{Kind: KindFunc, Name: "Load", Action: Added, Signature: "func Load()"},
+		{Kind: KindFunc, Name: "Run", Action: Modified, Signature: "func Run(opts Options) error", OldSignature: "func Run(name string) error"},
+		{Kind: KindInterfaceMethod, Name: "Engine.Close", Action: Added, Signature: "Close() error"},
+		{Kind: KindInterfaceMethod, Name: "Engine.Name", Action: Removed, Signature: "Name() string"},
+		{Kind: KindMethod, Name: "Options.Validate", Action: Modified, Signature: "func (o *Options) Validate() error", OldSignature: "func (o *Options) Validate() error"},
+		{Kind: KindType, Name: "Mode", Action: Modified, Signature: "type Mode string", OldSignature: "type Mode int"},
+	}
+	for i := range want {
+		want[i].File = "app/app.go"
+		want[i].Package = "app"
+	}
+	if !slices.Equal(changes, want) {
+		t.Fatalf("Compare =\n%+v\nwant\n%+v", changes, want)
+	}
+	for _, c := range changes {
+		if got := c.SignatureChanged(); got != (c.Name == "Run" || c.Name == "Mode") {
+			t.Errorf("%s SignatureChanged = %v", c.Name, got)
+		}
+	}
+}
+
+func TestCompareAddedAndDeletedFiles(t *testing.T) {
+	src := []byte("package tool\n\nfunc Exported() {}\n\ntype T struct{ Field string }\n")
+
+	added, err := Compare("tool.go", nil, src)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(added) != 3 || added[0].Action != Added || added[0].Package != "tool" {
+		t.Fatalf("added file changes = %+v", added)
+	}
+
+	removed, err := Compare("tool.go", src, nil)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(removed) != 3 || removed[0].Action != Removed || removed[0].Package != "tool" {
+		t.Fatalf("deleted file changes = %+v", removed)
+	}
+}
+
+func TestCompareGenericReceiver(t *testing.T) {
+	oldSrc := []byte("package list\n\ntype List[T any] struct{}\n\nfunc (l *List[T]) Len() int { return 0 }\n")
+	newSrc := []byte("package list\n\ntype List[T any] struct{}\n\nfunc (l *List[T]) Len() int { return 1 }\n")
+	changes, err := Compare("list.go", oldSrc, newSrc)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Name != "List.Len" || changes[0].SignatureChanged() {
+		t.Fatalf("changes = %+v", changes)
+	}
+}
+
+func TestCompareConstsAndVars(t *testing.T) {
+	oldSrc := []byte(`package store
+
+import "errors"
+
+const DefaultTimeout = 30
+
+const (
+	ModeA Mode = iota
+	ModeB
+)
+
+var ErrNotFound = errors.New("not found")
+
+var Limit, maxSize int = 10, 20
+`)
+	newSrc := []byte(`package store
+
+import "errors"
+
+const (
+	ModeA Mode = iota
+	ModeB
+	ModeC
+)
+
+var ErrMissing = errors.New("not found")
+
+var Limit, maxSize int64 = 10, 20
+
+const Version = "2"
+`)
+	changes, err := Compare("store.go", oldSrc, newSrc)
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	want := []Change{
+		{Kind: KindConst, Name: "DefaultTimeout", Action: Removed, Signature: "const DefaultTimeout"},
+		{Kind: KindConst, Name: "ModeC", Action: Added, Signature: "const ModeC"},
+		{Kind: KindConst, Name: "Version", Action: Added, Signature: "const Version"},
+		{Kind: KindVar, Name: "ErrMissing", Action: Added, Signature: "var ErrMissing"},
+		{Kind: KindVar, Name: "ErrNotFound", Action: Removed, Signature: "var ErrNotFound"},
+		{Kind: KindVar, Name: "Limit", Action: Modified, Signature: "var Limit int64", OldSignature: "var Limit int"},
+	}
+	for i := range want {
+		want[i].File = "store.go"
+		want[i].Package = "store"
+	}
+	if !slices.Equal(changes, want) {
+		t.Fatalf("Compare =\n%+v\nwant\n%+v", changes, want)
+	}
+	for _, c := range changes {
+		if got := c.Breaking(); got != (c.Name == "DefaultTimeout" || c.Name == "ErrNotFound" || c.Name == "Limit") {
+			t.Errorf("%s Breaking = %v", c.Name, got)
+		}
+	}
+}
+
+func TestCompareParseError(t *testing.T) {
+	if _, err := Compare("bad.go", nil, []byte("package bad\n\nfunc {")); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestChangeBreaking(t *testing.T) {
+	tests := []struct {
+		change Change
+		want   bool
+	}{
+		{Change{Kind: KindFunc, Action: Removed}, true},
+		{Change{Kind: KindFunc, Action: Added}, false},
+		{Change{Kind: KindInterfaceMethod, Action: Added}, true},
+		{Change{Kind: KindFunc, Action: Modified, Signature: "func F(int)", OldSignature: "func F()"}, true},
+		{Change{Kind: KindFunc, Action: Modified, Signature: "func F()", OldSignature: "func F()"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.change.Breaking(); got != tt.want {
+			t.Errorf("%+v Breaking = %v, want %v", tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestChangeDescribe(t *testing.T) {
+	c := Change{Package: "app", Kind: KindFunc, Name: "Run", Action: Modified, Signature: "func Run(opts Options) error", OldSignature: "func Run(name string) error"}
+	want := `changed func app.Run from "func Run(name string) error" to "func Run(opts Options) error"`
+	if got := c.Describe(); got != want {
+		t.Fatalf("Describe = %q, want %q", got, want)
+	}
+}
+
+func TestImportable(t *testing.T) {
+	tests := []struct {
+		path, pkg string
+		want      bool
+	}{
+		{"api.go", "lib", true},
+		{"pkg/client/client.go", "client", true},
+		{"internal/app/app.go", "app", false},
+		{"pkg/internal/x.go", "x", false},
+		{"cmd/tool/main.go", "main", false},
+		{"internal.go", "lib", true},
+	}
+	for _, tt := range tests {
+		if got := Importable(tt.path, tt.pkg); got != tt.want {
+			t.Errorf("Importable(%q, %q) = %v, want %v", tt.path, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	tests := map[string]string{
+		"module example.com/foo\n\ngo 1.22\n":          "example.com/foo",
+		"// comment\nmodule \"example.com/v2\" // x\n": "example.com/v2",
+		"go 1.22\n":                 "",
+		"modules example.com/foo\n": "",
+	}
+	for src, want := range tests {
+		if got := ModulePath([]byte(src)); got != want {
+			t.Errorf("ModulePath(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestMergeMoves(t *testing.T) {
+	changes := []Change{
+		{File: "lib/a.go", Package: "lib", Kind: KindFunc, Name: "Parse", Action: Removed, Signature: "func Parse(s string) error"},
+		{File: "lib/a.go", Package: "lib", Kind: KindFunc, Name: "Gone", Action: Removed, Signature: "func Gone()"},
+		{File: "lib/b.go", Package: "lib", Kind: KindFunc, Name: "Parse", Action: Added, Signature: "func Parse(s string) error"},
+		{File: "other/b.go", Package: "other", Kind: KindFunc, Name: "Gone", Action: Added, Signature: "func Gone()"},
+	}
+	got := MergeMoves(changes)
+	want := []Change{
+		{File: "lib/a.go", Package: "lib", Kind: KindFunc, Name: "Gone", Action: Removed, Signature: "func Gone()"},
+		{File: "lib/b.go", Package: "lib", Kind: KindFunc, Name: "Parse", Action: Modified, Signature: "func Parse(s string) error", OldSignature: "func Parse(s string) error"},
+		{File: "other/b.go", Package: "other", Kind: KindFunc, Name: "Gone", Action: Added, Signature: "func Gone()"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("MergeMoves =\n%+v\nwant\n%+v", got, want)
+	}
+	if got[1].Breaking() {
+		t.Fatal("moved declaration with unchanged signature should not be breaking")
+	}
+}
@@ -0,0 +1,89 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"git-ai-commit/internal/message"
+)
+
+// Placements accepted by the issues.placement setting.
+const (
+	PlacementTrailer = "trailer" // append a trailer such as "Refs: PROJ-1234"
+	PlacementPrefix  = "prefix"  // prefix the subject line
+	PlacementNone    = "none"    // only tell the model about the keys
+)
+
+// Defaults for unset issues settings.
+const (
+	DefaultTrailer      = "Refs"
+	DefaultPrefixFormat = "[{{key}}]"
+)
+
+// ValidatePlacement returns an error if placement is not a recognised
+// placement. An empty placement means PlacementTrailer.
+func ValidatePlacement(placement string) error {
+	switch placement {
+	case "", PlacementTrailer, PlacementPrefix, PlacementNone:
+		return nil
+	}
+	return fmt.Errorf("invalid issues.placement %q: must be %q, %q or %q", placement, PlacementTrailer, PlacementPrefix, PlacementNone)
+}
+
+// Extract returns the distinct issue keys that patterns find in a branch
+// name, in order. A pattern with a capture group yields the first group,
+// otherwise the whole match.
+func Extract(branch string, patterns []string) ([]string, error) {
+	var keys []string
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issues.branch_patterns entry %q: %w", pattern, err)
+		}
+		for _, m := range re.FindAllStringSubmatch(branch, -1) {
+			key := m[0]
+			if len(m) > 1 {
+				key = m[1]
+			}
+			if key != "" && !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// Prefix prepends to the subject of msg the prefix rendered from format,
+// with {{key}} replaced by the comma-separated keys. In a Conventional
+// Commits style subject the prefix goes after the header, so that
+// "feat(cli): add export" becomes "feat(cli): [PROJ-1] add export". A
+// message that already has the prefix is returned unchanged.
+func Prefix(msg string, keys []string, format string) string {
+	if len(keys) == 0 {
+		return msg
+	}
+	if format == "" {
+		format = DefaultPrefixFormat
+	}
+	prefix := strings.ReplaceAll(format, "{{key}}", strings.Join(keys, ", "))
+	header, rest := message.SplitHeader(msg)
+	if strings.HasPrefix(rest, prefix) {
+		return msg
+	}
+	return header + prefix + " " + rest
+}
+
+// Trailers returns one "key: issue" trailer per issue key, using
+// DefaultTrailer when key is empty.
+func Trailers(keys []string, key string) []string {
+	if key == "" {
+		key = DefaultTrailer
+	}
+	var trailers []string
+	for _, k := range keys {
+		trailers = append(trailers, key+": "+k)
+	}
+	return trailers
+}
@@ -0,0 +1,67 @@
+package issue
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		branch   string
+		patterns []string
+		want     []string
+	}{
+		{"feature/PROJ-1234-add-export", []string{`[A-Z][A-Z0-9]+-\d+`}, []string{"PROJ-1234"}},
+		{"fix/PROJ-1-and-PROJ-2", []string{`[A-Z][A-Z0-9]+-\d+`}, []string{"PROJ-1", "PROJ-2"}},
+		{"issue-42-crash", []string{`^issue-(\d+)`, `PROJ-\d+`}, []string{"42"}},
+		{"main", []string{`[A-Z][A-Z0-9]+-\d+`}, nil},
+	}
+	for _, tt := range tests {
+		got, err := Extract(tt.branch, tt.patterns)
+		if err != nil {
+			t.Fatalf("Extract(%q) error: %v", tt.branch, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Extract(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+	if _, err := Extract("main", []string{"("}); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	keys := []string{"PROJ-1"}
+	tests := []struct {
+		name   string
+		msg    string
+		format string
+		want   string
+	}{
+		{"default", "Add export\n\nBody.", "", "[PROJ-1] Add export\n\nBody."},
+		{"custom", "add export", "{{key}}:", "PROJ-1: add export"},
+		{"present", "[PROJ-1] Add export", "", "[PROJ-1] Add export"},
+		{"conventional", "feat(cli)!: add export\n\nBody.", "", "feat(cli)!: [PROJ-1] add export\n\nBody."},
+		{"conventional present", "fix: [PROJ-1] add export", "", "fix: [PROJ-1] add export"},
+	}
+	for _, tt := range tests {
+		if got := Prefix(tt.msg, keys, tt.format); got != tt.want {
+			t.Errorf("%s: Prefix = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := Prefix("Add export", []string{"A-1", "B-2"}, ""); got != "[A-1, B-2] Add export" {
+		t.Errorf("Prefix with two keys = %q", got)
+	}
+	if got := Prefix("Add export", nil, ""); got != "Add export" {
+		t.Errorf("Prefix without keys = %q", got)
+	}
+}
+
+func TestTrailers(t *testing.T) {
+	if got, want := Trailers([]string{"A-1", "B-2"}, ""), []string{"Refs: A-1", "Refs: B-2"}; !slices.Equal(got, want) {
+		t.Errorf("Trailers = %q, want %q", got, want)
+	}
+	if got, want := Trailers([]string{"A-1"}, "Jira"), []string{"Jira: A-1"}; !slices.Equal(got, want) {
+		t.Errorf("Trailers = %q, want %q", got, want)
+	}
+}
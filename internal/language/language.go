@@ -0,0 +1,136 @@
+package language
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Language is a language the commit message can be written in.
+type Language struct {
+	Code    string
+	Name    string
+	Scripts []*unicode.RangeTable // scripts its text is written in
+}
+
+// String returns the language as named in the prompt, e.g. "Japanese (ja)".
+func (l Language) String() string {
+	if l.Code == "" {
+		return l.Name
+	}
+	return l.Name + " (" + l.Code + ")"
+}
+
+var (
+	latin    = []*unicode.RangeTable{unicode.Latin}
+	cyrillic = []*unicode.RangeTable{unicode.Cyrillic}
+)
+
+// known lists the languages whose script can be checked.
+var known = []Language{
+	{"en", "English", latin},
+	{"de", "German", latin},
+	{"fr", "French", latin},
+	{"es", "Spanish", latin},
+	{"it", "Italian", latin},
+	{"pt", "Portuguese", latin},
+	{"nl", "Dutch", latin},
+	{"sv", "Swedish", latin},
+	{"pl", "Polish", latin},
+	{"tr", "Turkish", latin},
+	{"vi", "Vietnamese", latin},
+	{"id", "Indonesian", latin},
+	{"ja", "Japanese", []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana}},
+	{"zh", "Chinese", []*unicode.RangeTable{unicode.Han}},
+	{"ko", "Korean", []*unicode.RangeTable{unicode.Hangul, unicode.Han}},
+	{"ru", "Russian", cyrillic},
+	{"uk", "Ukrainian", cyrillic},
+	{"bg", "Bulgarian", cyrillic},
+	{"el", "Greek", []*unicode.RangeTable{unicode.Greek}},
+	{"ar", "Arabic", []*unicode.RangeTable{unicode.Arabic}},
+	{"fa", "Persian", []*unicode.RangeTable{unicode.Arabic}},
+	{"he", "Hebrew", []*unicode.RangeTable{unicode.Hebrew}},
+	{"hi", "Hindi", []*unicode.RangeTable{unicode.Devanagari}},
+	{"th", "Thai", []*unicode.RangeTable{unicode.Thai}},
+}
+
+// Lookup finds a language by its code, such as "ja" or "pt-BR", or by its
+// English name. Other values are returned as a language named value, whose
+// script is not known.
+func Lookup(value string) Language {
+	value = strings.TrimSpace(value)
+	base, _, _ := strings.Cut(strings.ReplaceAll(value, "_", "-"), "-")
+	for _, l := range known {
+		if strings.EqualFold(l.Name, value) {
+			return l
+		}
+		if strings.EqualFold(l.Code, base) {
+			l.Code = value
+			return l
+		}
+	}
+	return Language{Name: value}
+}
+
+// MinLetters is the number of letters below which a text is too short to
+// tell its script.
+const MinLetters = 8
+
+// Share is the fraction of letters a text must have in the language's
+// scripts to count as written in it.
+const Share = 0.5
+
+var (
+	// codeSpan matches inline code in backticks.
+	codeSpan = regexp.MustCompile("`[^`\n]*`")
+	// asciiRun matches a run of ASCII word characters, which may be an
+	// identifier, a path or a plain word.
+	asciiRun = regexp.MustCompile(`[A-Za-z0-9_./]+`)
+	// codeLike matches the parts of a run that make it an identifier or a
+	// path rather than a word: underscores, slashes, digits, dots between
+	// letters and camelCase.
+	codeLike = regexp.MustCompile(`[_/0-9]|[A-Za-z0-9]\.[A-Za-z0-9]|[a-z][A-Z]`)
+)
+
+// stripCode removes inline code and identifier-like words, such as
+// "Config.Load", "prompt_template" or "DetectedPrompt", which stay
+// untranslated in any language.
+func stripCode(text string) string {
+	text = codeSpan.ReplaceAllString(text, " ")
+	return asciiRun.ReplaceAllStringFunc(text, func(run string) string {
+		if codeLike.MatchString(run) {
+			return " "
+		}
+		return run
+	})
+}
+
+// Check returns an error if text, with at least MinLetters letters, is not
+// predominantly written in the scripts of l. Inline code and identifiers
+// are not counted. Languages with an unknown script always pass.
+func Check(text string, l Language) error {
+	if len(l.Scripts) == 0 {
+		return nil
+	}
+	text = stripCode(text)
+	letters, matched := 0, 0
+	for _, r := range text {
+		// Letters of the Common script, such as the Japanese prolonged
+		// sound mark, belong to no language in particular.
+		if !unicode.IsLetter(r) || unicode.Is(unicode.Common, r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, l.Scripts...) {
+			matched++
+		}
+	}
+	if letters < MinLetters {
+		return nil
+	}
+	if share := float64(matched) / float64(letters); share < Share {
+		return fmt.Errorf("only %.0f%% of the letters are in the script of %s", share*100, l)
+	}
+	return nil
+}
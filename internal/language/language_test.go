@@ -0,0 +1,46 @@
+package language
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		value, want string
+		script      bool
+	}{
+		{"ja", "Japanese (ja)", true},
+		{"pt-BR", "Portuguese (pt-BR)", true},
+		{"zh_TW", "Chinese (zh_TW)", true},
+		{"japanese", "Japanese (ja)", true},
+		{"Klingon", "Klingon", false},
+	}
+	for _, tt := range tests {
+		l := Lookup(tt.value)
+		if l.String() != tt.want || (len(l.Scripts) > 0) != tt.script {
+			t.Errorf("Lookup(%q) = %s (scripts: %d), want %s", tt.value, l, len(l.Scripts), tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		text, lang string
+		ok         bool
+	}{
+		{"キャッシュの無効化を修正し、サーバーの再起動を不要にする", "ja", true},
+		{"Redis キャッシュの TTL を短くする", "ja", true},
+		{"Fix cache invalidation after restarts", "ja", false},
+		{"Fix cache invalidation after restarts", "en", true},
+		{"Исправить сброс кэша", "ru", true},
+		{"Config.Load の DetectedPrompt を修正", "ja", true},
+		{"設定読み込みでprompt_templateとcontext_providersを扱うよう修正", "ja", true},
+		{"`git.RecentCommits` の `cmd.Dir` をリポジトリのルートにする", "ja", true},
+		{"Fix loadConfig for prompt_template after restarts", "ja", false},
+		{"Fix it", "ja", true}, // too short to tell
+		{"Fix cache invalidation after restarts", "Klingon", true},
+	}
+	for _, tt := range tests {
+		if err := Check(tt.text, Lookup(tt.lang)); (err == nil) != tt.ok {
+			t.Errorf("Check(%q, %s) = %v, want ok %v", tt.text, tt.lang, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+)
+
+// breakingFooterTokens are the footer tokens Conventional Commits accepts for
+// breaking changes.
+var breakingFooterTokens = []string{"BREAKING CHANGE:", "BREAKING-CHANGE:"}
+
+// HasBreakingFooter reports whether a commit message has a BREAKING CHANGE
+// footer below its subject line.
+func HasBreakingFooter(msg string) bool {
+	lines := strings.Split(msg, "\n")
+	for _, line := range lines[1:] {
+		for _, token := range breakingFooterTokens {
+			if strings.HasPrefix(strings.TrimSpace(line), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Kind classifies a message generated without an engine, so that it can be
+// styled for the configured prompt preset.
+type Kind struct {
+	Type  string // Conventional Commits type, e.g. "chore"
+	Scope string // scope, e.g. "deps"; required by the karma preset
+	Emoji string // gitmoji emoji
+}
+
+// Subject styles an imperative, lowercase subject line for the named prompt
+// preset. Unknown presets and custom prompts get Git's default style.
+func Subject(preset string, kind Kind, subject string) string {
+	switch preset {
+	case "conventional", "karma":
+		if kind.Scope != "" {
+			return kind.Type + "(" + kind.Scope + "): " + subject
+		}
+		return kind.Type + ": " + subject
+	case "gitmoji":
+		return kind.Emoji + " " + subject
+	}
+	if subject == "" {
+		return subject
+	}
+	return strings.ToUpper(subject[:1]) + subject[1:]
+}
+
+var headerPattern = regexp.MustCompile(`^[A-Za-z]+(?:\(([^)]*)\))?!?: `)
+
+// HeaderScopes returns the comma-separated scopes of a Conventional Commits
+// style subject line such as "feat(cli,config): ...". The boolean result is
+// false when the subject has no such header.
+func HeaderScopes(msg string) ([]string, bool) {
+	subject, _, _ := strings.Cut(msg, "\n")
+	m := headerPattern.FindStringSubmatch(subject)
+	if m == nil {
+		return nil, false
+	}
+	var scopes []string
+	for _, s := range strings.Split(m[1], ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, true
+}
+
+// SplitHeader splits a Conventional Commits style header such as
+// "feat(cli): " from the rest of msg. The header is "" when the subject has
+// none.
+func SplitHeader(msg string) (header, rest string) {
+	subject, _, _ := strings.Cut(msg, "\n")
+	if loc := headerPattern.FindStringIndex(subject); loc != nil {
+		return msg[:loc[1]], msg[loc[1]:]
+	}
+	return "", msg
+}
@@ -0,0 +1,78 @@
+package message
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHasBreakingFooter(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"feat: add option\n\nBREAKING CHANGE: Run now takes Options", true},
+		{"feat: add option\n\nBody.\n\nBREAKING-CHANGE: removed Load", true},
+		{"BREAKING CHANGE: subject only", false},
+		{"feat!: add option", false},
+		{"fix: typo\n\nNot a BREAKING CHANGE: mid-line", false},
+	}
+	for _, tt := range tests {
+		if got := HasBreakingFooter(tt.msg); got != tt.want {
+			t.Errorf("HasBreakingFooter(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestSubject(t *testing.T) {
+	kind := Kind{Type: "chore", Scope: "deps", Emoji: "⬆️"}
+	tests := map[string]string{
+		"":             "Bump foo from 1.0 to 1.1",
+		"default":      "Bump foo from 1.0 to 1.1",
+		"conventional": "chore(deps): bump foo from 1.0 to 1.1",
+		"karma":        "chore(deps): bump foo from 1.0 to 1.1",
+		"gitmoji":      "⬆️ bump foo from 1.0 to 1.1",
+	}
+	for preset, want := range tests {
+		if got := Subject(preset, kind, "bump foo from 1.0 to 1.1"); got != want {
+			t.Errorf("Subject(%q) = %q, want %q", preset, got, want)
+		}
+	}
+	if got := Subject("conventional", Kind{Type: "style"}, "format code"); got != "style: format code" {
+		t.Errorf("Subject without scope = %q", got)
+	}
+}
+
+func TestHeaderScopes(t *testing.T) {
+	tests := []struct {
+		msg    string
+		scopes []string
+		ok     bool
+	}{
+		{"feat(cli): add flag", []string{"cli"}, true},
+		{"fix(cli, config)!: handle errors\n\nbody", []string{"cli", "config"}, true},
+		{"docs: update README", nil, true},
+		{"Update README", nil, false},
+		{"✨ add flag", nil, false},
+	}
+	for _, tt := range tests {
+		scopes, ok := HeaderScopes(tt.msg)
+		if ok != tt.ok || !slices.Equal(scopes, tt.scopes) {
+			t.Errorf("HeaderScopes(%q) = %v, %v, want %v, %v", tt.msg, scopes, ok, tt.scopes, tt.ok)
+		}
+	}
+}
+
+func TestSplitHeader(t *testing.T) {
+	tests := []struct{ msg, header, rest string }{
+		{"feat(config): read the config file", "feat(config): ", "read the config file"},
+		{"fix!: drop config\n\nBody", "fix!: ", "drop config\n\nBody"},
+		{"Update config", "", "Update config"},
+		{"Fix (config\nfoo): bar", "", "Fix (config\nfoo): bar"},
+	}
+	for _, tt := range tests {
+		header, rest := SplitHeader(tt.msg)
+		if header != tt.header || rest != tt.rest {
+			t.Errorf("SplitHeader(%q) = %q, %q, want %q, %q", tt.msg, header, rest, tt.header, tt.rest)
+		}
+	}
+}
@@ -10,24 +10,123 @@ import (
 //go:embed prompt.tmpl
 var promptTemplateText string
 
-var promptTemplate = template.Must(template.New("prompt").Parse(promptTemplateText))
+var funcs = template.FuncMap{"join": strings.Join}
+
+var defaultTemplate = &Template{tmpl: template.Must(template.New("prompt").Funcs(funcs).Parse(promptTemplateText))}
+
+// Template is a parsed prompt template, either the default one or a
+// prompt_template file.
+type Template struct {
+	tmpl *template.Template
+}
+
+// Default returns the built-in prompt template.
+func Default() *Template {
+	return defaultTemplate
+}
+
+// Parse parses a prompt template. name appears in error messages, so it is
+// usually the path of the template file. Templates get the same functions
+// as the default template.
+func Parse(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Execute renders the template with data.
+func (t *Template) Execute(data PromptData) (string, error) {
+	data.SystemPrompt = strings.TrimSpace(data.SystemPrompt)
+	data.Context = strings.TrimSpace(data.Context)
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
 
 type PromptData struct {
 	SystemPrompt string
 	Context      string
+	Sections     []ContextSection // Output of the configured context providers
 	Diff         string
+	Files        []FileChange  // Per-file change summary, including filtered files
+	Declarations []DeclChange  // Exported Go declarations added, removed or modified
+	Breaking     []string      // Detected changes that break importers of the module
+	Dependencies []string      // Dependencies added, removed, upgraded or downgraded
+	Scopes       []string      // Canonical scopes of the changed files
+	Examples     []string      // Recent commit messages showing the repository's style
+	History      []FileHistory // Recent commits touching each changed file
+	Issues       []string      // Issue keys the change belongs to
+	IssuesAdded  bool          // Whether the issue keys are added to the message after generation
+	Template     string        // The repository's commit.template, giving the required format
+	CommentChar  string        // Comment character of the template
+	Glossary     Glossary      // The project's terminology
+	Language     string        // Language of the message, e.g. "Japanese (ja)"
+	SubjectLang  string        // Language of the subject line, when it differs
+	Previous     string        // Message being revised from the editor
+	Revisions    []string      // Instructions for revising Previous
+
+	// Fields for prompt_template files; the default template does not use them.
+	Branch       string   // Current branch, empty when HEAD is detached
+	Repository   string   // Name of the repository's top-level directory
+	Paths        []string // Paths of the changed files, including filtered files
+	Diffstat     string   // Per-file line counts and totals, as shown in the editor
+	FilterNotice string   // Files excluded, summarized or truncated; also appended to Diff
+	Subjects     []string // Subjects of recent commits, newest first
 }
 
-func Build(systemPrompt, context, diff string) string {
-	data := PromptData{
-		SystemPrompt: strings.TrimSpace(systemPrompt),
-		Context:      strings.TrimSpace(context),
-		Diff:         diff,
-	}
-	var buf bytes.Buffer
-	if err := promptTemplate.Execute(&buf, data); err != nil {
-		// Fallback to simple concatenation on template error
-		return systemPrompt + "\n\n" + context + "\n\n" + diff
-	}
-	return buf.String()
+// FileChange summarises one changed file for the prompt.
+type FileChange struct {
+	Path    string
+	OldPath string // Source path for renames and copies
+	Status  string // added, modified, deleted, renamed, copied or type changed
+	Added   int
+	Deleted int
+	Binary  bool
+	Filter  string // How the file was filtered from the diff, e.g. "excluded from diff"
+}
+
+// Glossary is the project's terminology.
+type Glossary struct {
+	Prefer      []Preference
+	Forbidden   []string
+	Definitions []Definition
+}
+
+// Preference names the term to write instead of an avoided one.
+type Preference struct {
+	Avoid string
+	Use   string
+}
+
+// Definition explains a project term.
+type Definition struct {
+	Term    string
+	Meaning string
+}
+
+// ContextSection is extra context gathered by a provider.
+type ContextSection struct {
+	Name string
+	Text string
+}
+
+// FileHistory lists recent commits that touched one changed file.
+type FileHistory struct {
+	Path     string
+	Subjects []string // newest first
+	Issues   []string // issue references found in those commits, e.g. "#12"
+}
+
+// DeclChange describes a change to one exported Go declaration.
+type DeclChange struct {
+	Package      string
+	Kind         string // func, method, type, field or interface method
+	Name         string // qualified within the package, e.g. "Config.Load"
+	Action       string // added, removed or modified
+	Signature    string
+	OldSignature string // set when a modified declaration changed its signature
 }
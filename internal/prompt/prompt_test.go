@@ -6,7 +6,7 @@ import (
 )
 
 func TestBuild(t *testing.T) {
-	got := Build("sys", "ctx", "diff")
+	got := mustBuild(t, "sys", "ctx", "diff")
 
 	// Check that output contains expected sections
 	if !strings.Contains(got, "=== INSTRUCTIONS ===") {
@@ -36,7 +36,7 @@ func TestBuild(t *testing.T) {
 }
 
 func TestBuildWithoutContext(t *testing.T) {
-	got := Build("sys", "", "diff")
+	got := mustBuild(t, "sys", "", "diff")
 
 	// Check that output omits CONTEXT section when empty
 	if strings.Contains(got, "=== CONTEXT ===") {
@@ -51,7 +51,7 @@ func TestBuildWithoutContext(t *testing.T) {
 }
 
 func TestBuildOutputRules(t *testing.T) {
-	got := Build("sys", "ctx", "diff")
+	got := mustBuild(t, "sys", "ctx", "diff")
 
 	// Verify output rules are included
 	if !strings.Contains(got, "Exclude explanations, preambles") {
@@ -61,3 +61,249 @@ func TestBuildOutputRules(t *testing.T) {
 		t.Fatal("Build output should contain direct start instruction")
 	}
 }
+
+func TestRenderChangeSummary(t *testing.T) {
+	got := mustRender(t, PromptData{
+		SystemPrompt: "sys",
+		Diff:         "diff",
+		Files: []FileChange{
+			{Path: "main.go", Status: "modified", Added: 3, Deleted: 1},
+			{Path: "go.sum", Status: "modified", Added: 12, Deleted: 8, Filter: "excluded from diff"},
+			{Path: "new.go", OldPath: "old.go", Status: "renamed"},
+			{Path: "logo.png", Status: "added", Binary: true},
+		},
+	})
+
+	want := "=== CHANGE SUMMARY ===\n" +
+		"- modified main.go: +3 -1\n" +
+		"- modified go.sum: +12 -8 (excluded from diff)\n" +
+		"- renamed old.go -> new.go: +0 -0\n" +
+		"- added logo.png: binary\n" +
+		"\n=== GIT DIFF ==="
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing change summary:\n%s", got)
+	}
+}
+
+func TestBuildWithoutChangeSummary(t *testing.T) {
+	got := mustBuild(t, "sys", "", "diff")
+	if strings.Contains(got, "=== CHANGE SUMMARY ===") {
+		t.Fatal("Build output should not contain CHANGE SUMMARY section without files")
+	}
+}
+
+func TestRenderDeclarationChanges(t *testing.T) {
+	got := mustRender(t, PromptData{
+		SystemPrompt: "sys",
+		Diff:         "diff",
+		Declarations: []DeclChange{
+			{Package: "app", Kind: "func", Name: "Run", Action: "modified", Signature: "func Run(opts Options) error", OldSignature: "func Run(name string) error"},
+			{Package: "app", Kind: "field", Name: "Options.Amend", Action: "added", Signature: "Amend bool"},
+			{Package: "config", Kind: "method", Name: "Config.Load", Action: "modified", Signature: "func (c Config) Load() error"},
+		},
+	})
+
+	want := "=== GO DECLARATION CHANGES ===\n" +
+		"- modified func app.Run: func Run(name string) error -> func Run(opts Options) error\n" +
+		"- added field app.Options.Amend: Amend bool\n" +
+		"- modified method config.Config.Load: func (c Config) Load() error\n" +
+		"\n=== GIT DIFF ==="
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing declaration changes:\n%s", got)
+	}
+}
+
+func TestRenderBreakingChanges(t *testing.T) {
+	got := mustRender(t, PromptData{
+		SystemPrompt: "sys",
+		Diff:         "diff",
+		Breaking:     []string{"removed exported func lib.Parse"},
+	})
+	if !strings.Contains(got, "=== BREAKING CHANGES ===\n") || !strings.Contains(got, "- removed exported func lib.Parse\n\n=== GIT DIFF ===") {
+		t.Fatalf("Render output missing breaking changes:\n%s", got)
+	}
+	if strings.Contains(mustBuild(t, "sys", "", "diff"), "BREAKING CHANGES") {
+		t.Fatal("Build output should not contain BREAKING CHANGES section without breaks")
+	}
+}
+
+func TestRenderDependencyChanges(t *testing.T) {
+	got := mustRender(t, PromptData{
+		SystemPrompt: "sys",
+		Diff:         "diff",
+		Dependencies: []string{"bump golang.org/x/text from v0.14.0 to v0.15.0 (go.mod)"},
+	})
+	want := "=== DEPENDENCY CHANGES ===\n- bump golang.org/x/text from v0.14.0 to v0.15.0 (go.mod)\n\n=== GIT DIFF ==="
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing dependency changes:\n%s", got)
+	}
+}
+
+func TestRenderScopes(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Scopes: []string{"cli", "config"}})
+	if !strings.Contains(got, "=== ALLOWED SCOPES ===\nIf the commit format uses a scope, use only these scopes: cli, config\n\n=== GIT DIFF ===") {
+		t.Fatalf("Render output missing scopes:\n%s", got)
+	}
+}
+
+func TestRenderExamples(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Examples: []string{"Add a", "Fix b\n\nBody."}})
+	want := "=== STYLE EXAMPLES ===\n" +
+		"Recent commit messages from this repository. Match their style, tone and format, not their content.\n" +
+		"---\nAdd a\n---\nFix b\n\nBody.\n---\n"
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing examples:\n%s", got)
+	}
+	if strings.Contains(mustBuild(t, "sys", "", "diff"), "STYLE EXAMPLES") {
+		t.Fatal("Build output should not contain STYLE EXAMPLES without examples")
+	}
+}
+
+func TestRenderHistory(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", History: []FileHistory{
+		{Path: "app.go", Subjects: []string{"Fix crash (#12)", "Add app"}, Issues: []string{"#12"}},
+		{Path: "README.md", Subjects: []string{"Document flags"}},
+	}})
+	want := "=== RELATED HISTORY ===\n" +
+		"Recent commits touching the changed files. Mention earlier work or issues only if this change continues it.\n" +
+		"app.go:\n- Fix crash (#12)\n- Add app\n  issues: #12\n" +
+		"README.md:\n- Document flags\n\n"
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing related history:\n%s", got)
+	}
+}
+
+func TestRenderIssues(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Issues: []string{"PROJ-1"}, IssuesAdded: true})
+	want := "=== ISSUES ===\nThis change belongs to PROJ-1. The reference is added to the message automatically; do not write it yourself.\n\n"
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing issues:\n%s", got)
+	}
+	got = mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Issues: []string{"PROJ-1", "PROJ-2"}})
+	if !strings.Contains(got, "This change belongs to PROJ-1, PROJ-2. Reference it as the commit format requires.\n") {
+		t.Fatalf("Render output missing issues for the model to reference:\n%s", got)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Template: "Subject\n\n# Why is this needed?", CommentChar: "#"})
+	want := "=== COMMIT TEMPLATE ===\n" +
+		"The repository's commit template. The message must follow its structure; lines starting with \"#\" are guidance, not content.\n" +
+		"Subject\n\n# Why is this needed?\n"
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing template:\n%s", got)
+	}
+	if strings.Contains(mustBuild(t, "sys", "", "diff"), "COMMIT TEMPLATE") {
+		t.Fatal("Build output should not contain COMMIT TEMPLATE without a template")
+	}
+}
+
+func TestRenderRevision(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Previous: "Update cache", Revisions: []string{"mention the invalidation"}})
+	want := "=== REVISION ===\n" +
+		"The user rejected this commit message:\n---\nUpdate cache\n---\n" +
+		"- mention the invalidation\n" +
+		"Write a new commit message for the same change, following these instructions.\n\n=== GIT DIFF ==="
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing revision:\n%s", got)
+	}
+	got = mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Previous: "Update cache"})
+	if !strings.Contains(got, "---\nWrite a new commit message for the same change.\n") {
+		t.Fatalf("Render output missing retry request:\n%s", got)
+	}
+}
+
+func TestRenderContextSections(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Context: "ctx", Diff: "diff", Sections: []ContextSection{
+		{Name: "tests", Text: "ok  pkg 0.1s"},
+		{Name: "ticket", Text: "Cache invalidation\nSee design doc"},
+	}})
+	want := "=== CONTEXT ===\nctx\n\n=== CONTEXT: tests ===\nok  pkg 0.1s\n\n=== CONTEXT: ticket ===\nCache invalidation\nSee design doc\n\n"
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing context sections:\n%s", got)
+	}
+}
+
+func TestRenderGlossary(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Glossary: Glossary{
+		Prefer:      []Preference{{Avoid: "config file", Use: "manifest"}},
+		Forbidden:   []string{"simply", "obviously"},
+		Definitions: []Definition{{Term: "manifest", Meaning: "the file listing the build inputs"}},
+	}})
+	want := "=== GLOSSARY ===\nUse the project's terminology.\n" +
+		"- Write \"manifest\", not \"config file\"\n" +
+		"- Never use: simply, obviously\n" +
+		"- manifest: the file listing the build inputs\n"
+	if !strings.Contains(got, want) {
+		t.Fatalf("Render output missing glossary:\n%s", got)
+	}
+	if got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff"}); strings.Contains(got, "GLOSSARY") {
+		t.Fatalf("Render output has an empty glossary:\n%s", got)
+	}
+}
+
+func TestRenderLanguage(t *testing.T) {
+	got := mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Language: "Japanese (ja)"})
+	if !strings.Contains(got, "- DO NOT use code fences or backticks\n- Write the message in Japanese (ja); keep code identifiers") {
+		t.Fatalf("Render output missing language rule:\n%s", got)
+	}
+	got = mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", Language: "Japanese (ja)", SubjectLang: "English (en)"})
+	if !strings.Contains(got, "- Write the message in Japanese (ja), except the subject line, which must be in English (en);") {
+		t.Fatalf("Render output missing bilingual rule:\n%s", got)
+	}
+	got = mustRender(t, PromptData{SystemPrompt: "sys", Diff: "diff", SubjectLang: "English (en)"})
+	if !strings.Contains(got, "- Write the subject line in English (en);") {
+		t.Fatalf("Render output missing subject language rule:\n%s", got)
+	}
+}
+
+func TestParseCustomTemplate(t *testing.T) {
+	tmpl, err := Parse("custom.tmpl", "{{.Repository}} on {{.Branch}}\n{{join .Paths \",\"}}\n{{.Diffstat}}\n{{.FilterNotice}}\n{{range .Subjects}}- {{.}}\n{{end}}{{.Language}}\n{{join .Issues \",\"}}")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	got, err := tmpl.Execute(PromptData{
+		Repository:   "app",
+		Branch:       "feature/PROJ-1",
+		Paths:        []string{"a.go", "b.go"},
+		Diffstat:     " a.go | +1 -0",
+		FilterNotice: "Excluded files: go.sum",
+		Subjects:     []string{"Add a", "Fix b"},
+		Language:     "Japanese (ja)",
+		Issues:       []string{"PROJ-1"},
+	})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	want := "app on feature/PROJ-1\na.go,b.go\n a.go | +1 -0\nExcluded files: go.sum\n- Add a\n- Fix b\nJapanese (ja)\nPROJ-1"
+	if got != want {
+		t.Fatalf("Execute = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if _, err := Parse("custom.tmpl", "{{.Diff"); err == nil || !strings.Contains(err.Error(), "custom.tmpl") {
+		t.Fatalf("Parse error = %v, want a syntax error naming the template", err)
+	}
+	tmpl, err := Parse("custom.tmpl", "{{.Difff}}")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, err := tmpl.Execute(PromptData{Diff: "diff"}); err == nil || !strings.Contains(err.Error(), "Difff") {
+		t.Fatalf("Execute error = %v, want an error naming the unknown field", err)
+	}
+}
+
+func mustBuild(t *testing.T, systemPrompt, context, diff string) string {
+	t.Helper()
+	return mustRender(t, PromptData{SystemPrompt: systemPrompt, Context: context, Diff: diff})
+}
+
+func mustRender(t *testing.T, data PromptData) string {
+	t.Helper()
+	got, err := Default().Execute(data)
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	return got
+}
@@ -0,0 +1,294 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mode values accepted by the redact.mode setting.
+const (
+	ModeMask  = "mask"  // replace secrets with placeholders and warn
+	ModeBlock = "block" // refuse to send a diff containing secrets
+	ModeOff   = "off"   // disable redaction
+)
+
+// minSecretEntropy is the minimum Shannon entropy, in bits per character, of
+// a value assigned to a secret-like name before it is treated as a secret.
+const minSecretEntropy = 3.5
+
+// Finding describes one distinct secret that was redacted.
+type Finding struct {
+	Kind        string // detector that matched, e.g. "aws-access-key"
+	Placeholder string // text substituted for the secret
+	Count       int    // number of occurrences replaced
+}
+
+type detector struct {
+	kind string
+	re   *regexp.Regexp
+	// group is the submatch holding the secret; 0 redacts the whole match.
+	group int
+	// check, if set, must accept the secret for it to be redacted.
+	check func(string) bool
+}
+
+var builtinDetectors = []detector{
+	{kind: "aws-access-key", re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{kind: "gcp-api-key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{kind: "github-token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{kind: "slack-token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`)},
+	// Quoted values assigned to secret-like names, in code or config.
+	{
+		kind:  "secret-assignment",
+		re:    regexp.MustCompile(secretName + `["']?\s*(?::=|=|:)\s*["']([A-Za-z0-9+/=_\-.~]{16,})["']`),
+		group: 1,
+		check: isHighEntropy,
+	},
+	// Unquoted values running to the end of the line, as in .env or YAML
+	// files. Requiring the end of line keeps code such as
+	// "token := src.Token()" from matching.
+	{
+		kind:  "secret-assignment",
+		re:    regexp.MustCompile(secretName + `\s*[=:]\s*([A-Za-z0-9+/=_\-.~]{16,})\s*$`),
+		group: 1,
+		check: isHighEntropy,
+	},
+}
+
+// secretName matches identifiers and keys that name a secret.
+const secretName = `(?i)[\w.-]*(?:secret|token|passwd|password|api[_-]?key|access[_-]?key|auth[_-]?key|credential|private[_-]?key)[\w.-]*`
+
+func isHighEntropy(s string) bool {
+	return entropy(s) >= minSecretEntropy
+}
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----`)
+	// privateKeyInline matches a whole key on one line, with escaped
+	// newlines.
+	privateKeyInline = regexp.MustCompile(`(-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----)(.*?)(-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----)`)
+)
+
+// Redactor replaces secrets with stable placeholders. The same secret is
+// always replaced by the same placeholder, across all texts passed to one
+// Redactor.
+type Redactor struct {
+	detectors    []detector
+	placeholders map[string]string // secret -> placeholder
+	findings     map[string]*Finding
+	counts       map[string]int // kind -> number of distinct secrets
+}
+
+// New returns a Redactor using the built-in detectors plus the given custom
+// regexes. A custom regex with a capture group redacts only the first group.
+func New(patterns []string) (*Redactor, error) {
+	detectors := append([]detector(nil), builtinDetectors...)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		group := 0
+		if re.NumSubexp() > 0 {
+			group = 1
+		}
+		detectors = append(detectors, detector{kind: "custom", re: re, group: group})
+	}
+	return &Redactor{
+		detectors:    detectors,
+		placeholders: map[string]string{},
+		findings:     map[string]*Finding{},
+		counts:       map[string]int{},
+	}, nil
+}
+
+// ValidateMode returns an error if mode is not a recognised redact mode. An
+// empty mode is accepted and means ModeMask.
+func ValidateMode(mode string) error {
+	switch mode {
+	case "", ModeMask, ModeBlock, ModeOff:
+		return nil
+	}
+	return fmt.Errorf("invalid redact.mode %q: must be %q, %q or %q", mode, ModeMask, ModeBlock, ModeOff)
+}
+
+// Redact returns text with all detected secrets replaced.
+//
+// A private key is redacted between its BEGIN and END lines, or within one
+// line when it is written with escaped newlines, as in JSON credentials.
+// When no END line follows, as in a partial hunk or a truncated diff,
+// everything after BEGIN is redacted up to the end of the hunk.
+func (r *Redactor) Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if privateKeyInline.MatchString(line) {
+			out = append(out, r.redactLine(r.redactInlineKeys(line)))
+			continue
+		}
+		if loc := privateKeyBegin.FindStringIndex(line); loc != nil {
+			inDiff := diffPrefix(line) != ""
+			end := i + 1
+			for end < len(lines) && !privateKeyEnd.MatchString(lines[end]) && !(inDiff && isHunkEnd(lines[end])) {
+				end++
+			}
+			closed := end < len(lines) && privateKeyEnd.MatchString(lines[end])
+			head, rest := line[:loc[1]], line[loc[1]:]
+			body := lines[i+1 : end]
+			secret := rest + "\n" + strings.Join(body, "\n")
+			if hasKeyMaterial(rest) {
+				head += r.placeholder("private-key", secret)
+			} else {
+				head += rest
+			}
+			out = append(out, head)
+			if len(body) > 0 {
+				out = append(out, diffPrefix(body[0])+r.placeholder("private-key", secret))
+			}
+			if closed {
+				out = append(out, lines[end])
+				i = end
+			} else {
+				i = end - 1
+			}
+			continue
+		}
+		out = append(out, r.redactLine(line))
+	}
+	return strings.Join(out, "\n")
+}
+
+// redactInlineKeys replaces the key material between BEGIN and END markers
+// on the same line.
+func (r *Redactor) redactInlineKeys(line string) string {
+	return privateKeyInline.ReplaceAllStringFunc(line, func(match string) string {
+		sub := privateKeyInline.FindStringSubmatch(match)
+		if !hasKeyMaterial(sub[2]) {
+			return match
+		}
+		return sub[1] + r.placeholder("private-key", sub[2]) + sub[3]
+	})
+}
+
+// hasKeyMaterial reports whether s holds more than the escaped newlines,
+// quotes and punctuation around a key.
+func hasKeyMaterial(s string) bool {
+	s = strings.ReplaceAll(s, `\n`, "")
+	return strings.Trim(s, "\"',; \t") != ""
+}
+
+// isHunkEnd reports whether line ends the hunk it follows: a new hunk or
+// file header, or a line that is not part of a diff, such as the truncation
+// notice.
+func isHunkEnd(line string) bool {
+	return strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "diff --git ") ||
+		(diffPrefix(line) == "" && !strings.HasPrefix(line, "\\"))
+}
+
+func (r *Redactor) redactLine(line string) string {
+	for _, d := range r.detectors {
+		line = d.re.ReplaceAllStringFunc(line, func(match string) string {
+			secret := match
+			if d.group > 0 {
+				sub := d.re.FindStringSubmatch(match)
+				if len(sub) <= d.group || sub[d.group] == "" {
+					return match
+				}
+				secret = sub[d.group]
+			}
+			if strings.HasPrefix(secret, "[REDACTED:") {
+				return match
+			}
+			if d.check != nil && !d.check(secret) {
+				return match
+			}
+			return strings.Replace(match, secret, r.placeholder(d.kind, secret), 1)
+		})
+	}
+	return line
+}
+
+func (r *Redactor) placeholder(kind, secret string) string {
+	if p, ok := r.placeholders[secret]; ok {
+		r.findings[p].Count++
+		return p
+	}
+	r.counts[kind]++
+	p := fmt.Sprintf("[REDACTED:%s#%d]", kind, r.counts[kind])
+	r.placeholders[secret] = p
+	r.findings[p] = &Finding{Kind: kind, Placeholder: p, Count: 1}
+	return p
+}
+
+// Findings returns the distinct secrets redacted so far, ordered by
+// placeholder.
+func (r *Redactor) Findings() []Finding {
+	out := make([]Finding, 0, len(r.findings))
+	for _, f := range r.findings {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Placeholder < out[j].Placeholder })
+	return out
+}
+
+// FormatReport renders findings as one line per redacted secret.
+func FormatReport(findings []Finding) string {
+	var b strings.Builder
+	for _, f := range findings {
+		fmt.Fprintf(&b, "  %s (%s, %d occurrence", f.Placeholder, f.Kind, f.Count)
+		if f.Count != 1 {
+			b.WriteString("s")
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// Kinds returns the distinct detector kinds among findings, sorted.
+func Kinds(findings []Finding) []string {
+	seen := map[string]bool{}
+	var kinds []string
+	for _, f := range findings {
+		if !seen[f.Kind] {
+			seen[f.Kind] = true
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// diffPrefix returns the unified diff marker of line, if any.
+func diffPrefix(line string) string {
+	if line != "" && strings.ContainsRune("+- ", rune(line[0])) {
+		return line[:1]
+	}
+	return ""
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	freq := map[rune]int{}
+	for _, c := range s {
+		freq[c]++
+	}
+	n := float64(len([]rune(s)))
+	var h float64
+	for _, count := range freq {
+		p := float64(count) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
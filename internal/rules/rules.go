@@ -0,0 +1,225 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"git-ai-commit/internal/deps"
+	"git-ai-commit/internal/git"
+	"git-ai-commit/internal/message"
+)
+
+// Input describes the change a rule inspects.
+type Input struct {
+	Preset         string         // prompt preset used to style the message
+	Files          []git.FileStat // changed files
+	Diff           string         // diff sent to the engine
+	WhitespaceOnly bool           // nothing but whitespace changed
+	Deps           []deps.Change  // dependency manifest changes
+	DepsOnly       bool           // nothing but dependencies changed
+	Scopes         []string       // scopes the [scopes] settings give the change, if any
+}
+
+// Rule deterministically generates a commit message for a kind of trivial
+// change. Generate reports false when the rule does not apply.
+type Rule struct {
+	Name     string
+	Generate func(in Input) (string, bool)
+}
+
+// Builtin lists the built-in rules in the order they are tried.
+var Builtin = []Rule{
+	{Name: "whitespace", Generate: whitespace},
+	{Name: "rename", Generate: rename},
+	{Name: "lockfile", Generate: lockfile},
+	{Name: "deps", Generate: dependencies},
+	{Name: "version", Generate: version},
+}
+
+// Names returns the names of the built-in rules.
+func Names() []string {
+	names := make([]string, 0, len(Builtin))
+	for _, r := range Builtin {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// Validate returns an error if settings names an unknown rule.
+func Validate(settings map[string]bool) error {
+	known := Names()
+	for name := range settings {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("unknown rule %q in rules: must be one of %s", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// Match returns the message generated by the first enabled rule that
+// applies, with the rule's name. Rules are opt-in: a rule is enabled only
+// when settings maps its name to true.
+func Match(in Input, settings map[string]bool) (string, string, bool) {
+	if len(in.Files) == 0 {
+		return "", "", false
+	}
+	for _, r := range Builtin {
+		if !settings[r.Name] {
+			continue
+		}
+		if msg, ok := r.Generate(in); ok {
+			return msg, r.Name, true
+		}
+	}
+	return "", "", false
+}
+
+// indentationSensitive lists file extensions where whitespace changes can
+// change meaning.
+var indentationSensitive = []string{".py", ".yaml", ".yml", ".mk", ".haml", ".pug", ".coffee", ".nim", ".sass", ".slim"}
+
+func whitespace(in Input) (string, bool) {
+	if !in.WhitespaceOnly {
+		return "", false
+	}
+	allGo := true
+	for _, f := range in.Files {
+		// A file without changed lines changed only its mode.
+		if f.Status != "modified" || f.Binary || f.Added+f.Deleted == 0 {
+			return "", false
+		}
+		base := path.Base(f.Path)
+		if base == "Makefile" || slices.Contains(indentationSensitive, path.Ext(base)) {
+			return "", false
+		}
+		if path.Ext(base) != ".go" {
+			allGo = false
+		}
+	}
+	kind := message.Kind{Type: "style", Scope: scope(in, topDir(in.Files)), Emoji: "🎨"}
+	if allGo {
+		return message.Subject(in.Preset, kind, "format code with gofmt"), true
+	}
+	return message.Subject(in.Preset, kind, "fix whitespace"), true
+}
+
+func rename(in Input) (string, bool) {
+	for _, f := range in.Files {
+		if f.Status != "renamed" || f.Added != 0 || f.Deleted != 0 {
+			return "", false
+		}
+	}
+	kind := message.Kind{Type: "refactor", Scope: scope(in, topDir(in.Files)), Emoji: "🚚"}
+	if len(in.Files) == 1 {
+		f := in.Files[0]
+		return message.Subject(in.Preset, kind, fmt.Sprintf("rename %s to %s", f.OldPath, f.Path)), true
+	}
+	var b strings.Builder
+	b.WriteString(message.Subject(in.Preset, kind, fmt.Sprintf("rename %d files", len(in.Files))))
+	b.WriteString("\n")
+	for _, f := range in.Files {
+		fmt.Fprintf(&b, "\n- %s -> %s", f.OldPath, f.Path)
+	}
+	return b.String(), true
+}
+
+func lockfile(in Input) (string, bool) {
+	for _, f := range in.Files {
+		if !deps.IsLockFile(f.Path) || f.Status != "modified" {
+			return "", false
+		}
+	}
+	kind := message.Kind{Type: "chore", Scope: scope(in, "deps"), Emoji: "📌"}
+	if len(in.Files) == 1 {
+		return message.Subject(in.Preset, kind, "update "+in.Files[0].Path), true
+	}
+	var b strings.Builder
+	b.WriteString(message.Subject(in.Preset, kind, "update lock files"))
+	b.WriteString("\n")
+	for _, f := range in.Files {
+		fmt.Fprintf(&b, "\n- %s", f.Path)
+	}
+	return b.String(), true
+}
+
+func dependencies(in Input) (string, bool) {
+	if !in.DepsOnly || len(in.Deps) == 0 {
+		return "", false
+	}
+	return deps.Message(in.Preset, scope(in, "deps"), in.Deps), true
+}
+
+var versionPattern = regexp.MustCompile(`v?\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?`)
+
+// versionFiles hold nothing but the project's version.
+var versionFiles = []string{"VERSION", "VERSION.txt", "version.txt", ".version"}
+
+// versionManifests declare the project's version in a "version" field.
+var versionManifests = []string{"package.json", "composer.json", "manifest.json", "Cargo.toml", "pyproject.toml", "setup.cfg", "Chart.yaml", "pubspec.yaml", "galaxy.yml"}
+
+// versionField matches a manifest's version field, e.g. `"version": "1.2.0"`
+// or `version = "1.2.0"`.
+var versionField = regexp.MustCompile(`^\s*["']?version["']?\s*[:=]`)
+
+// version matches a single-line change to a version file, or to the
+// version field of a known manifest, that only replaces the version number.
+func version(in Input) (string, bool) {
+	if len(in.Files) != 1 || in.Files[0].Status != "modified" || in.Files[0].Added != 1 || in.Files[0].Deleted != 1 {
+		return "", false
+	}
+	base := path.Base(in.Files[0].Path)
+	manifest := slices.Contains(versionManifests, base)
+	if !manifest && !slices.Contains(versionFiles, base) {
+		return "", false
+	}
+	var removed, added string
+	for _, line := range strings.Split(in.Diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++"):
+		case strings.HasPrefix(line, "-"):
+			removed = line[1:]
+		case strings.HasPrefix(line, "+"):
+			added = line[1:]
+		}
+	}
+	if manifest && (!versionField.MatchString(removed) || !versionField.MatchString(added)) {
+		return "", false
+	}
+	oldVersion, newVersion := versionPattern.FindString(removed), versionPattern.FindString(added)
+	if oldVersion == "" || newVersion == "" || oldVersion == newVersion {
+		return "", false
+	}
+	if versionPattern.ReplaceAllString(removed, "") != versionPattern.ReplaceAllString(added, "") {
+		return "", false
+	}
+	kind := message.Kind{Type: "chore", Scope: scope(in, "release"), Emoji: "🔖"}
+	return message.Subject(in.Preset, kind, "bump version to "+newVersion), true
+}
+
+// scope returns the scope of a generated message: the one the [scopes]
+// settings give the change, none when they give several, or else fallback.
+func scope(in Input, fallback string) string {
+	switch len(in.Scopes) {
+	case 0:
+		return fallback
+	case 1:
+		return in.Scopes[0]
+	}
+	return ""
+}
+
+// topDir returns the top-level directory shared by all files, or "".
+func topDir(files []git.FileStat) string {
+	var dir string
+	for _, f := range files {
+		top, _, found := strings.Cut(f.Path, "/")
+		if !found || (dir != "" && top != dir) {
+			return ""
+		}
+		dir = top
+	}
+	return dir
+}
@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"git-ai-commit/internal/deps"
+	"git-ai-commit/internal/git"
+)
+
+// allRules enables every built-in rule.
+var allRules = map[string]bool{"whitespace": true, "rename": true, "lockfile": true, "deps": true, "version": true}
+
+func TestMatchWhitespace(t *testing.T) {
+	in := Input{
+		Preset:         "conventional",
+		Files:          []git.FileStat{{Path: "internal/app/app.go", Status: "modified", Added: 3, Deleted: 3}},
+		WhitespaceOnly: true,
+	}
+	msg, rule, ok := Match(in, allRules)
+	if !ok || rule != "whitespace" || msg != "style(internal): format code with gofmt" {
+		t.Fatalf("Match = %q, %q, %v", msg, rule, ok)
+	}
+
+	in.Files = append(in.Files, git.FileStat{Path: "README.md", Status: "modified", Added: 1, Deleted: 1})
+	if msg, _, _ := Match(in, allRules); msg != "style: fix whitespace" {
+		t.Fatalf("Match = %q", msg)
+	}
+
+	in.Files = []git.FileStat{{Path: "tool.py", Status: "modified", Added: 1, Deleted: 1}}
+	if _, _, ok := Match(in, allRules); ok {
+		t.Fatal("whitespace rule should not apply to indentation-sensitive files")
+	}
+
+	in.Files = []git.FileStat{{Path: "run.sh", Status: "modified"}}
+	if _, _, ok := Match(in, allRules); ok {
+		t.Fatal("whitespace rule should not apply to mode changes")
+	}
+}
+
+func TestMatchScopes(t *testing.T) {
+	in := Input{
+		Preset:         "conventional",
+		Files:          []git.FileStat{{Path: "internal/app/app.go", Status: "modified", Added: 3, Deleted: 3}},
+		WhitespaceOnly: true,
+		Scopes:         []string{"app"},
+	}
+	if msg, _, _ := Match(in, allRules); msg != "style(app): format code with gofmt" {
+		t.Fatalf("Match = %q", msg)
+	}
+
+	in.Scopes = []string{"app", "config"}
+	if msg, _, _ := Match(in, allRules); msg != "style: format code with gofmt" {
+		t.Fatalf("Match = %q, want no scope for several", msg)
+	}
+
+	in = Input{Preset: "conventional", Files: []git.FileStat{{Path: "go.sum", Status: "modified", Added: 4, Deleted: 2}}, Scopes: []string{"build"}}
+	if msg, _, _ := Match(in, allRules); msg != "chore(build): update go.sum" {
+		t.Fatalf("Match = %q", msg)
+	}
+}
+
+func TestMatchRename(t *testing.T) {
+	in := Input{Files: []git.FileStat{{Path: "bar.go", OldPath: "foo.go", Status: "renamed"}}}
+	msg, rule, ok := Match(in, allRules)
+	if !ok || rule != "rename" || msg != "Rename foo.go to bar.go" {
+		t.Fatalf("Match = %q, %q, %v", msg, rule, ok)
+	}
+
+	in.Files = append(in.Files, git.FileStat{Path: "b.go", OldPath: "a.go", Status: "renamed"})
+	msg, _, _ = Match(in, allRules)
+	if msg != "Rename 2 files\n\n- foo.go -> bar.go\n- a.go -> b.go" {
+		t.Fatalf("Match = %q", msg)
+	}
+
+	in.Files[1].Added = 1
+	if _, _, ok := Match(in, allRules); ok {
+		t.Fatal("rename rule should not apply to renames with edits")
+	}
+}
+
+func TestMatchLockfile(t *testing.T) {
+	in := Input{Preset: "gitmoji", Files: []git.FileStat{{Path: "go.sum", Status: "modified", Added: 4, Deleted: 2}}}
+	msg, rule, ok := Match(in, allRules)
+	if !ok || rule != "lockfile" || msg != "📌 update go.sum" {
+		t.Fatalf("Match = %q, %q, %v", msg, rule, ok)
+	}
+}
+
+func TestMatchDeps(t *testing.T) {
+	in := Input{
+		Preset:   "conventional",
+		Files:    []git.FileStat{{Path: "go.mod", Status: "modified", Added: 1, Deleted: 1}, {Path: "go.sum", Status: "modified"}},
+		Deps:     []deps.Change{{Manifest: "go.mod", Name: "example.com/x", Action: deps.Upgraded, From: "v1.0.0", To: "v1.2.0"}},
+		DepsOnly: true,
+	}
+	msg, rule, ok := Match(in, allRules)
+	if !ok || rule != "deps" || msg != "chore(deps): bump example.com/x from v1.0.0 to v1.2.0" {
+		t.Fatalf("Match = %q, %q, %v", msg, rule, ok)
+	}
+	if _, _, ok := Match(in, map[string]bool{"deps": false}); ok {
+		t.Fatal("disabled rule should not apply")
+	}
+	if _, _, ok := Match(in, nil); ok {
+		t.Fatal("rules should not apply unless enabled")
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	diff := "diff --git a/package.json b/package.json\n--- a/package.json\n+++ b/package.json\n@@ -2,3 +2,3 @@\n   \"name\": \"app\",\n-  \"version\": \"1.4.2\",\n+  \"version\": \"1.5.0\",\n"
+	in := Input{
+		Files: []git.FileStat{{Path: "package.json", Status: "modified", Added: 1, Deleted: 1}},
+		Diff:  diff,
+	}
+	msg, rule, ok := Match(in, allRules)
+	if !ok || rule != "version" || msg != "Bump version to 1.5.0" {
+		t.Fatalf("Match = %q, %q, %v", msg, rule, ok)
+	}
+
+	in.Diff = strings.Replace(diff, `+  "version": "1.5.0",`, `+  "release": "1.5.0",`, 1)
+	if _, _, ok := Match(in, allRules); ok {
+		t.Fatal("version rule should not apply when more than the version changed")
+	}
+
+	in.Files[0].Path = "VERSION"
+	in.Diff = "--- a/VERSION\n+++ b/VERSION\n@@ -1 +1 @@\n-1.4.2\n+1.5.0\n"
+	if msg, _, ok := Match(in, allRules); !ok || msg != "Bump version to 1.5.0" {
+		t.Fatalf("Match = %q, %v", msg, ok)
+	}
+
+	in.Files[0].Path = "config.go"
+	in.Diff = "--- a/config.go\n+++ b/config.go\n@@ -3 +3 @@\n-const defaultHost = \"10.0.0.1\"\n+const defaultHost = \"10.0.1.1\"\n"
+	if _, _, ok := Match(in, allRules); ok {
+		t.Fatal("version rule should not apply to dotted numbers outside version files")
+	}
+
+	in.Files[0].Path = "package.json"
+	in.Diff = "--- a/package.json\n+++ b/package.json\n@@ -3 +3 @@\n-  \"host\": \"10.0.0.1\",\n+  \"host\": \"10.0.1.1\",\n"
+	if _, _, ok := Match(in, allRules); ok {
+		t.Fatal("version rule should not apply to manifest fields other than version")
+	}
+}
+
+func TestMatchNoRule(t *testing.T) {
+	in := Input{Files: []git.FileStat{{Path: "main.go", Status: "modified", Added: 10, Deleted: 2}}}
+	if _, _, ok := Match(in, allRules); ok {
+		t.Fatal("expected no rule to apply")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(map[string]bool{"rename": false, "deps": true}); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if err := Validate(map[string]bool{"typo": false}); err == nil {
+		t.Fatal("expected error for unknown rule")
+	}
+}
@@ -0,0 +1,81 @@
+package scope
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"git-ai-commit/internal/git"
+)
+
+// Derive modes accepted by the scopes.derive setting.
+const (
+	DerivePackage   = "package"   // Go package name, else top-level directory
+	DeriveDirectory = "directory" // top-level directory
+)
+
+// ValidateDerive returns an error if mode is not a recognised derive mode.
+// An empty mode disables derivation.
+func ValidateDerive(mode string) error {
+	switch mode {
+	case "", DerivePackage, DeriveDirectory:
+		return nil
+	}
+	return fmt.Errorf("invalid scopes.derive %q: must be %q or %q", mode, DerivePackage, DeriveDirectory)
+}
+
+// Infer returns the sorted, distinct scopes of the changed files. Each file
+// takes the scope of the longest glob in paths that matches it, or else a
+// scope derived from its path according to derive. Files with neither
+// contribute no scope.
+func Infer(files []string, paths map[string]string, derive string) []string {
+	patterns := make([]string, 0, len(paths))
+	for pattern := range paths {
+		patterns = append(patterns, pattern)
+	}
+	// Longest, and so most specific, pattern first; ties in lexical order.
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, file := range files {
+		s := ""
+		for _, pattern := range patterns {
+			if git.MatchesAnyPattern(file, []string{pattern}) {
+				s = paths[pattern]
+				break
+			}
+		}
+		if s == "" {
+			s = derived(file, derive)
+		}
+		if s != "" && !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+func derived(file, derive string) string {
+	dir := path.Dir(file)
+	switch derive {
+	case DerivePackage:
+		if strings.HasSuffix(file, ".go") && dir != "." {
+			return path.Base(dir)
+		}
+		fallthrough
+	case DeriveDirectory:
+		if top, _, found := strings.Cut(file, "/"); found {
+			return top
+		}
+	}
+	return ""
+}
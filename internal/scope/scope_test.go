@@ -0,0 +1,51 @@
+package scope
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInferPaths(t *testing.T) {
+	paths := map[string]string{
+		"cmd/**":                 "cli",
+		"internal/**":            "core",
+		"internal/config/**":     "config",
+		"internal/config/*.tmpl": "templates",
+	}
+	files := []string{"cmd/git-ai-commit/main.go", "internal/config/config.go", "internal/app/app.go", "README.md"}
+	got := Infer(files, paths, "")
+	if want := []string{"cli", "config", "core"}; !slices.Equal(got, want) {
+		t.Fatalf("Infer = %v, want %v", got, want)
+	}
+}
+
+func TestInferDerive(t *testing.T) {
+	files := []string{"internal/app/app.go", "internal/app/app_test.go", "docs/usage.md", "README.md"}
+	if got, want := Infer(files, nil, DerivePackage), []string{"app", "docs"}; !slices.Equal(got, want) {
+		t.Fatalf("Infer(package) = %v, want %v", got, want)
+	}
+	if got, want := Infer(files, nil, DeriveDirectory), []string{"docs", "internal"}; !slices.Equal(got, want) {
+		t.Fatalf("Infer(directory) = %v, want %v", got, want)
+	}
+	if got := Infer(files, nil, ""); got != nil {
+		t.Fatalf("Infer without paths or derive = %v, want none", got)
+	}
+}
+
+func TestInferPathsTakePrecedence(t *testing.T) {
+	got := Infer([]string{"cmd/tool/main.go"}, map[string]string{"cmd/**": "cli"}, DerivePackage)
+	if !slices.Equal(got, []string{"cli"}) {
+		t.Fatalf("Infer = %v", got)
+	}
+}
+
+func TestValidateDerive(t *testing.T) {
+	for _, mode := range []string{"", DerivePackage, DeriveDirectory} {
+		if err := ValidateDerive(mode); err != nil {
+			t.Errorf("ValidateDerive(%q) error: %v", mode, err)
+		}
+	}
+	if err := ValidateDerive("module"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}